@@ -0,0 +1,99 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DeprecationRuleSpec defines a deprecated API version, it mirrors an entry of the versions file
+type DeprecationRuleSpec struct {
+	// APIVersion is the name of the deprecated API version such as "extensions/v1beta1"
+	APIVersion string `json:"apiVersion"`
+	// Kind is the Object type such as "Deployment" or "Ingress", a pattern such as "Flow*",
+	// or "*" for every kind of the API version. The list kinds match the rule of their item kind.
+	Kind string `json:"kind"`
+	// Kubernetes version in which the API version is introduced in, older versions don't serve it
+	// +kubebuilder:validation:Pattern=`^v?[0-9]+\.[0-9]+(\.[0-9]+)?$`
+	IntroducedInVersion string `json:"introducedInVersion,omitempty"`
+	// Kubernetes version in which the API version is deprecated in
+	// +kubebuilder:validation:Pattern=`^v?[0-9]+\.[0-9]+(\.[0-9]+)?$`
+	DeprecatedInVersion string `json:"deprecatedInVersion,omitempty"`
+	// Kubernetes version in which the API version is removed in
+	// +kubebuilder:validation:Pattern=`^v?[0-9]+\.[0-9]+(\.[0-9]+)?$`
+	RemovedInVersion string `json:"removedInVersion,omitempty"`
+	// ReplacementAPI is the new supported API version
+	ReplacementAPI string `json:"replacementApi,omitempty"`
+	// Severity is how urgent the migration away from the API version is
+	// +kubebuilder:validation:Enum=info;warning;critical
+	Severity string `json:"severity,omitempty"`
+	// MigrationNote is a short note on how to migrate to the replacement
+	MigrationNote string `json:"migrationNote,omitempty"`
+	// MigrationGuide is the URL of the upstream migration guide
+	// +kubebuilder:validation:Pattern=`^https?://.+`
+	MigrationGuide string `json:"migrationGuide,omitempty"`
+	// FieldChanges are the notable field changes between the API version and the replacement
+	FieldChanges []string `json:"fieldChanges,omitempty"`
+	// Component is the name of the component serving the API version such as "k8s" or "cert-manager",
+	// its versions are compared with the installed version of the component
+	// +kubebuilder:default=k8s
+	Component string `json:"component,omitempty"`
+}
+
+// DeprecationRuleStatus defines the observed state of DeprecationRule
+type DeprecationRuleStatus struct {
+	// Conditions report whether the rule is valid and loaded into the dataset, an invalid rule is skipped
+	// and the message of its Valid condition tells why
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=dr
+//+kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="API-Version",type=string,JSONPath=`.spec.apiVersion`
+// +kubebuilder:printcolumn:name="Kind",type=string,JSONPath=`.spec.kind`
+// +kubebuilder:printcolumn:name="Component",type=string,JSONPath=`.spec.component`,priority=10
+// +kubebuilder:printcolumn:name="Introduced-In",type=string,JSONPath=`.spec.introducedInVersion`,priority=10
+// +kubebuilder:printcolumn:name="Deprecated-In",type=string,JSONPath=`.spec.deprecatedInVersion`
+// +kubebuilder:printcolumn:name="Removed-In",type=string,JSONPath=`.spec.removedInVersion`
+// +kubebuilder:printcolumn:name="Replacement",type=string,JSONPath=`.spec.replacementApi`
+// +kubebuilder:printcolumn:name="Severity",type=string,JSONPath=`.spec.severity`
+// +kubebuilder:printcolumn:name="Migration-Guide",type=string,JSONPath=`.spec.migrationGuide`,priority=10
+// +kubebuilder:printcolumn:name="Valid",type=string,JSONPath=`.status.conditions[?(@.type=="Valid")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// DeprecationRule adds or overrides a deprecated API version of the versions file
+type DeprecationRule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DeprecationRuleSpec   `json:"spec,omitempty"`
+	Status DeprecationRuleStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// DeprecationRuleList contains a list of DeprecationRule
+type DeprecationRuleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DeprecationRule `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DeprecationRule{}, &DeprecationRuleList{})
+}
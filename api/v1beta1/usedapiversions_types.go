@@ -28,15 +28,34 @@ type UsedApiVersionsSpec struct {
 
 	// UsedApiVersions is a list of API versions
 	UsedApiVersions []APIVersionMeta `json:"usedApiVersions,omitempty"`
+	// LookAheadReleases is the number of releases ahead the removals of the API versions are looked for,
+	// the --look-ahead-releases flag of the manager when it's not set
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	LookAheadReleases *int `json:"lookAheadReleases,omitempty"`
+	// TargetVersions are the Kubernetes versions such as "v1.29.0" the API versions are also checked against,
+	// the --target-version flags of the manager when it's not set
+	// +optional
+	TargetVersions []string `json:"targetVersions,omitempty"`
 }
 
 // APIVersionMeta defines the used API version and Kind
 type APIVersionMeta struct {
 
 	// APIVersion is the name of the API version used by specific kind.
-	APIVersion string `json:"apiVersion,omitempty"`
+	APIVersion string `json:"apiVersion,omitempty" jsonschema:"required"`
 	// Kind is the Object type such as "Deployment" or "Ingress"
-	Kind string `json:"kind,omitempty"`
+	Kind string `json:"kind,omitempty" jsonschema:"required"`
+	// Component is the name of the component serving the API version such as "k8s" or "cert-manager".
+	// The API version is checked against the installed version of its component, empty matches any component.
+	Component string `json:"component,omitempty"`
+	// Fields are the paths of the fields used by the kind such as "spec.backend", checked against the deprecated fields
+	// +optional
+	Fields []string `json:"fields,omitempty"`
+	// Annotations are the keys of the annotations used by the kind such as "kubernetes.io/ingress.class", checked
+	// against the deprecated annotations
+	// +optional
+	Annotations []string `json:"annotations,omitempty"`
 }
 
 // UsedApiVersionsStatus defines the observed state of UsedApiVersions
@@ -45,6 +64,44 @@ type UsedApiVersionsStatus struct {
 	ApiVersionsStatus []APIVersionStatus `json:"apiVersionsStatus,omitempty"`
 	// FinalStatus is the overall status for all the used API versions
 	FinalStatus FinalStatusResult `json:"finalStatus,omitempty"`
+	// TargetVersionsStatus is the status of the API versions served by Kubernetes for every target Kubernetes version
+	TargetVersionsStatus []TargetVersionStatus `json:"targetVersionsStatus,omitempty"`
+}
+
+// TargetVersionStatus is the status of the API versions served by Kubernetes checked against a target Kubernetes version
+type TargetVersionStatus struct {
+	// Version is the target Kubernetes version
+	Version string `json:"version" yaml:"version"`
+	// Number of API Versions deprecated in the target version
+	Deprecated int `json:"deprecated" yaml:"deprecated"`
+	// Number of API Versions removed in the target version
+	Removed int `json:"removed" yaml:"removed"`
+	// Number of API Versions not available yet in the target version
+	NotYetAvailable int `json:"notYetAvailable" yaml:"notYetAvailable"`
+	// ApiVersions is the status of every API version in the target version
+	ApiVersions []TargetAPIVersionStatus `json:"apiVersions,omitempty" yaml:"apiVersions,omitempty"`
+	// Error is set when the API versions can't be checked against the target version, such as an invalid version
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// TargetAPIVersionStatus is the status of an API version in a target Kubernetes version
+type TargetAPIVersionStatus struct {
+	// APIVersion is the name of the apiVersion.
+	APIVersion string `json:"apiVersion" yaml:"apiVersion"`
+	// Kind is the Object type
+	Kind string `json:"kind" yaml:"kind"`
+	// Whether the API Version is deprecated in the target version or not
+	Deprecated bool `json:"deprecated" yaml:"deprecated"`
+	// Whether the API Version is removed in the target version or not
+	Removed bool `json:"removed" yaml:"removed"`
+	// Whether the API Version is introduced after the target version, so the target version doesn't serve it yet
+	NotYetAvailable bool `json:"notYetAvailable" yaml:"notYetAvailable"`
+	// Whether the deprecation or removal is inferred from the Kubernetes deprecation policy rather than the dataset
+	Inferred bool `json:"inferred,omitempty" yaml:"inferred,omitempty"`
+	// Whether the replacement is available in the target version: false when the replacement is removed in the
+	// target version or introduced after it, true when the dataset or the cluster serving it tells it's available.
+	// It's not set otherwise.
+	ReplacementAvailable *bool `json:"replacementAvailable,omitempty" yaml:"replacementAvailable,omitempty"`
 }
 
 // FinalStatusResult is the overall status for all the used API versions
@@ -53,10 +110,29 @@ type FinalStatusResult struct {
 	Deprecated int `json:"deprecated" yaml:"deprecated"`
 	// Number of removed API Versions
 	Removed int `json:"removed" yaml:"removed"`
+	// Number of API Versions introduced after the installed version, so not available yet
+	NotYetAvailable int `json:"notYetAvailable" yaml:"notYetAvailable"`
 	// Number of removed API Versions in the next release
 	RemovedInNextRelease int `json:"removedInNextRelease" yaml:"removedInNextRelease"`
 	// Number of removed API Versions in the next two releases
 	RemovedInNextTwoReleases int `json:"removedInNextTwoReleases" yaml:"removedInNextTwoReleases"`
+	// Number of API Versions removed within the look-ahead releases
+	RemovedWithinLookAhead int `json:"removedWithinLookAhead" yaml:"removedWithinLookAhead"`
+	// Number of API Versions whose serving by the cluster contradicts the dataset
+	ServedMismatch int `json:"servedMismatch" yaml:"servedMismatch"`
+	// Number of API Versions whose deprecation or removal is inferred from the Kubernetes deprecation policy
+	Inferred int `json:"inferred" yaml:"inferred"`
+	// Number of alpha API Versions inferred to be removable in any release without deprecation
+	Unstable int `json:"unstable" yaml:"unstable"`
+	// Number of deprecated fields and annotations
+	DeprecatedFields int `json:"deprecatedFields" yaml:"deprecatedFields"`
+	// Number of removed fields and annotations
+	RemovedFields int `json:"removedFields" yaml:"removedFields"`
+	// Severity is the most urgent severity of the deprecated, removed or soon removed API versions: info, warning
+	// or critical. It's not set when none of them has a severity.
+	Severity string `json:"severity,omitempty" yaml:"severity,omitempty"`
+	// LookAheadReleases is the number of releases ahead the removals are looked for
+	LookAheadReleases int `json:"lookAheadReleases" yaml:"lookAheadReleases"`
 }
 
 // APIVersionStatus defines the observed API version status
@@ -65,20 +141,94 @@ type APIVersionStatus struct {
 	APIVersion string `json:"apiVersion" yaml:"apiVersion"`
 	// Kind is the Object type
 	Kind string `json:"kind" yaml:"kind"`
+	// Whether the API Version is introduced after the installed version, so it's not available yet
+	NotYetAvailable bool `json:"notYetAvailable" yaml:"notYetAvailable"`
 	// Whether the API Version is deprecated or not
 	Deprecated bool `json:"deprecated" yaml:"deprecated"`
 	// Whether the API Version is removed or not
 	Removed bool `json:"removed" yaml:"removed"`
+	// Kubernetes version in which the API is introduced in
+	IntroducedInVersion string `json:"introducedInVersion" yaml:"introducedInVersion"`
 	// Kubernetes version in which the API is deprecated in
 	DeprecatedInVersion string `json:"deprecatedInVersion" yaml:"deprecatedInVersion"`
 	// Kubernetes version in which the API is removed in
 	RemovedInVersion string `json:"removedInVersion" yaml:"removedInVersion"`
+	// Whether the deprecatedInVersion or removedInVersion is inferred from the Kubernetes deprecation policy
+	// because the dataset doesn't list it, rather than curated in the dataset.
+	Inferred bool `json:"inferred,omitempty" yaml:"inferred,omitempty"`
+	// Whether the apiVersion is an alpha Kubernetes API version, inferred to be removable in any release
+	// without deprecation, so it's unstable even though it's neither deprecated nor removed.
+	Unstable bool `json:"unstable,omitempty" yaml:"unstable,omitempty"`
 	// ReplacementAPI is the new supported apiVersion.
 	ReplacementAPI string `json:"replacementApi" yaml:"replacementApi"`
+	// Whether the cluster serves the kind in the replacementApi, so it can be migrated now.
+	// It's not set when there is no replacement or the cluster can't be checked.
+	ReplacementAvailable *bool `json:"replacementAvailable,omitempty" yaml:"replacementAvailable,omitempty"`
+	// Severity is how urgent the migration away from the apiVersion is: info, warning or critical
+	Severity string `json:"severity,omitempty" yaml:"severity,omitempty"`
+	// MigrationNote is a short note on how to migrate to the replacementApi
+	MigrationNote string `json:"migrationNote,omitempty" yaml:"migrationNote,omitempty"`
+	// MigrationGuide is the URL of the upstream migration guide
+	MigrationGuide string `json:"migrationGuide,omitempty" yaml:"migrationGuide,omitempty"`
+	// FieldChanges are the notable field changes between the apiVersion and the replacementApi
+	FieldChanges []string `json:"fieldChanges,omitempty" yaml:"fieldChanges,omitempty"`
 	// Whether the apiVersion will be removed in the next release or not
 	RemovedInNextRelease bool `json:"removedInNextRelease" yaml:"removedInNextRelease"`
 	// Whether the apiVersion will be removed in the next release or not
 	RemovedInNextTwoReleases bool `json:"removedInNextTwoReleases" yaml:"removedInNextTwoReleases"`
+	// Whether the cluster serves the kind in the apiVersion, checked with the discovery API.
+	// It's not set when the cluster can't be checked.
+	Served *bool `json:"served,omitempty" yaml:"served,omitempty"`
+	// Whether the cluster serving the apiVersion or not contradicts the dataset, such as a removed apiVersion
+	// still served, or an apiVersion already introduced and not removed yet but not served.
+	ServedMismatch bool `json:"servedMismatch" yaml:"servedMismatch"`
+	// ReleasesUntilRemoval is the number of releases until the apiVersion is removed, 0 once it's removed.
+	// It's not set when the apiVersion is not removed in a known release.
+	ReleasesUntilRemoval *int `json:"releasesUntilRemoval,omitempty" yaml:"releasesUntilRemoval,omitempty"`
+	// Whether the apiVersion is removed within the look-ahead releases or not
+	RemovedWithinLookAhead bool `json:"removedWithinLookAhead" yaml:"removedWithinLookAhead"`
+	// RemovalDate is the date the cluster is expected to run the release which removes the apiVersion, such as
+	// 2023-07-28, from the planned upgrades and the upstream release calendar. It's not set when it's unknown.
+	RemovalDate string `json:"removalDate,omitempty" yaml:"removalDate,omitempty"`
+	// DaysUntilRemoval is the number of days until the removalDate, 0 once it's reached.
+	DaysUntilRemoval *int `json:"daysUntilRemoval,omitempty" yaml:"daysUntilRemoval,omitempty"`
+	// Source is the name of the deprecation source which supplied the matching rule
+	Source string `json:"source,omitempty" yaml:"source,omitempty"`
+	// Component is the name of the component serving the API version
+	Component string `json:"component,omitempty" yaml:"component,omitempty"`
+	// ComponentVersion is the installed version of the component the API version is checked against
+	ComponentVersion string `json:"componentVersion,omitempty" yaml:"componentVersion,omitempty"`
+	// FieldsStatus is the status of the fields and annotations used by the kind
+	FieldsStatus []FieldStatus `json:"fieldsStatus,omitempty" yaml:"fieldsStatus,omitempty"`
+}
+
+// FieldStatus defines the observed status of a field or annotation used by the kind
+type FieldStatus struct {
+	// Field is the path of the used field, empty for an annotation
+	Field string `json:"field,omitempty" yaml:"field,omitempty"`
+	// Annotation is the key of the used annotation, empty for a field
+	Annotation string `json:"annotation,omitempty" yaml:"annotation,omitempty"`
+	// Whether the field or annotation is deprecated or not
+	Deprecated bool `json:"deprecated" yaml:"deprecated"`
+	// Whether the field or annotation is removed or not
+	Removed bool `json:"removed" yaml:"removed"`
+	// Kubernetes version in which the field or annotation is deprecated in
+	DeprecatedInVersion string `json:"deprecatedInVersion" yaml:"deprecatedInVersion"`
+	// Kubernetes version in which the field or annotation is removed in
+	RemovedInVersion string `json:"removedInVersion" yaml:"removedInVersion"`
+	// Replacement is the field or annotation to use instead
+	Replacement string `json:"replacement" yaml:"replacement"`
+	// Whether the field or annotation will be removed in the next release or not
+	RemovedInNextRelease bool `json:"removedInNextRelease" yaml:"removedInNextRelease"`
+	// Whether the field or annotation will be removed in the next two releases or not
+	RemovedInNextTwoReleases bool `json:"removedInNextTwoReleases" yaml:"removedInNextTwoReleases"`
+	// ReleasesUntilRemoval is the number of releases until the field or annotation is removed, 0 once it's removed.
+	// It's not set when it's not removed in a known release.
+	ReleasesUntilRemoval *int `json:"releasesUntilRemoval,omitempty" yaml:"releasesUntilRemoval,omitempty"`
+	// Whether the field or annotation is removed within the look-ahead releases or not
+	RemovedWithinLookAhead bool `json:"removedWithinLookAhead" yaml:"removedWithinLookAhead"`
+	// Source is the name of the deprecation source which supplied the matching rule
+	Source string `json:"source,omitempty" yaml:"source,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -88,8 +238,15 @@ type APIVersionStatus struct {
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 // +kubebuilder:printcolumn:name="Deprecated",type=integer,JSONPath=`.status.finalStatus.deprecated`
 // +kubebuilder:printcolumn:name="Removed",type=integer,JSONPath=`.status.finalStatus.removed`
+// +kubebuilder:printcolumn:name="Severity",type=string,JSONPath=`.status.finalStatus.severity`
+// +kubebuilder:printcolumn:name="Not-Yet-Available",type=integer,JSONPath=`.status.finalStatus.notYetAvailable`,priority=10
 // +kubebuilder:printcolumn:name="Removed-NEXT-Release",type=integer,JSONPath=`.status.finalStatus.removedInNextRelease`,priority=10
 // +kubebuilder:printcolumn:name="Removed-NEXT-Two-Releases",type=integer,JSONPath=`.status.finalStatus.removedInNextTwoReleases`,priority=10
+// +kubebuilder:printcolumn:name="Removed-Within-Look-Ahead",type=integer,JSONPath=`.status.finalStatus.removedWithinLookAhead`,priority=10
+// +kubebuilder:printcolumn:name="Served-Mismatch",type=integer,JSONPath=`.status.finalStatus.servedMismatch`,priority=10
+// +kubebuilder:printcolumn:name="Inferred",type=integer,JSONPath=`.status.finalStatus.inferred`,priority=10
+// +kubebuilder:printcolumn:name="Deprecated-Fields",type=integer,JSONPath=`.status.finalStatus.deprecatedFields`,priority=10
+// +kubebuilder:printcolumn:name="Removed-Fields",type=integer,JSONPath=`.status.finalStatus.removedFields`,priority=10
 type UsedApiVersions struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
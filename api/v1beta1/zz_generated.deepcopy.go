@@ -21,12 +21,23 @@ limitations under the License.
 package v1beta1
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *APIVersionMeta) DeepCopyInto(out *APIVersionMeta) {
 	*out = *in
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIVersionMeta.
@@ -42,6 +53,38 @@ func (in *APIVersionMeta) DeepCopy() *APIVersionMeta {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *APIVersionStatus) DeepCopyInto(out *APIVersionStatus) {
 	*out = *in
+	if in.ReplacementAvailable != nil {
+		in, out := &in.ReplacementAvailable, &out.ReplacementAvailable
+		*out = new(bool)
+		**out = **in
+	}
+	if in.FieldChanges != nil {
+		in, out := &in.FieldChanges, &out.FieldChanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Served != nil {
+		in, out := &in.Served, &out.Served
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ReleasesUntilRemoval != nil {
+		in, out := &in.ReleasesUntilRemoval, &out.ReleasesUntilRemoval
+		*out = new(int)
+		**out = **in
+	}
+	if in.DaysUntilRemoval != nil {
+		in, out := &in.DaysUntilRemoval, &out.DaysUntilRemoval
+		*out = new(int)
+		**out = **in
+	}
+	if in.FieldsStatus != nil {
+		in, out := &in.FieldsStatus, &out.FieldsStatus
+		*out = make([]FieldStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIVersionStatus.
@@ -54,6 +97,127 @@ func (in *APIVersionStatus) DeepCopy() *APIVersionStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeprecationRule) DeepCopyInto(out *DeprecationRule) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeprecationRule.
+func (in *DeprecationRule) DeepCopy() *DeprecationRule {
+	if in == nil {
+		return nil
+	}
+	out := new(DeprecationRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeprecationRule) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeprecationRuleList) DeepCopyInto(out *DeprecationRuleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DeprecationRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeprecationRuleList.
+func (in *DeprecationRuleList) DeepCopy() *DeprecationRuleList {
+	if in == nil {
+		return nil
+	}
+	out := new(DeprecationRuleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeprecationRuleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeprecationRuleSpec) DeepCopyInto(out *DeprecationRuleSpec) {
+	*out = *in
+	if in.FieldChanges != nil {
+		in, out := &in.FieldChanges, &out.FieldChanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeprecationRuleSpec.
+func (in *DeprecationRuleSpec) DeepCopy() *DeprecationRuleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeprecationRuleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeprecationRuleStatus) DeepCopyInto(out *DeprecationRuleStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeprecationRuleStatus.
+func (in *DeprecationRuleStatus) DeepCopy() *DeprecationRuleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeprecationRuleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FieldStatus) DeepCopyInto(out *FieldStatus) {
+	*out = *in
+	if in.ReleasesUntilRemoval != nil {
+		in, out := &in.ReleasesUntilRemoval, &out.ReleasesUntilRemoval
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FieldStatus.
+func (in *FieldStatus) DeepCopy() *FieldStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FieldStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FinalStatusResult) DeepCopyInto(out *FinalStatusResult) {
 	*out = *in
@@ -69,6 +233,48 @@ func (in *FinalStatusResult) DeepCopy() *FinalStatusResult {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TargetAPIVersionStatus) DeepCopyInto(out *TargetAPIVersionStatus) {
+	*out = *in
+	if in.ReplacementAvailable != nil {
+		in, out := &in.ReplacementAvailable, &out.ReplacementAvailable
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetAPIVersionStatus.
+func (in *TargetAPIVersionStatus) DeepCopy() *TargetAPIVersionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TargetAPIVersionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TargetVersionStatus) DeepCopyInto(out *TargetVersionStatus) {
+	*out = *in
+	if in.ApiVersions != nil {
+		in, out := &in.ApiVersions, &out.ApiVersions
+		*out = make([]TargetAPIVersionStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetVersionStatus.
+func (in *TargetVersionStatus) DeepCopy() *TargetVersionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TargetVersionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UsedApiVersions) DeepCopyInto(out *UsedApiVersions) {
 	*out = *in
@@ -134,6 +340,18 @@ func (in *UsedApiVersionsSpec) DeepCopyInto(out *UsedApiVersionsSpec) {
 	if in.UsedApiVersions != nil {
 		in, out := &in.UsedApiVersions, &out.UsedApiVersions
 		*out = make([]APIVersionMeta, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LookAheadReleases != nil {
+		in, out := &in.LookAheadReleases, &out.LookAheadReleases
+		*out = new(int)
+		**out = **in
+	}
+	if in.TargetVersions != nil {
+		in, out := &in.TargetVersions, &out.TargetVersions
+		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
 }
@@ -154,9 +372,18 @@ func (in *UsedApiVersionsStatus) DeepCopyInto(out *UsedApiVersionsStatus) {
 	if in.ApiVersionsStatus != nil {
 		in, out := &in.ApiVersionsStatus, &out.ApiVersionsStatus
 		*out = make([]APIVersionStatus, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	out.FinalStatus = in.FinalStatus
+	if in.TargetVersionsStatus != nil {
+		in, out := &in.TargetVersionsStatus, &out.TargetVersionsStatus
+		*out = make([]TargetVersionStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UsedApiVersionsStatus.
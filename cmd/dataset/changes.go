@@ -0,0 +1,128 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/wayfair-incubator/k8s-used-api-versions/pkg/deprecation"
+)
+
+// changeSymbols are the diff symbols of the change types
+var changeSymbols = map[deprecation.ChangeType]string{
+	deprecation.Added:    "+",
+	deprecation.Removed:  "-",
+	deprecation.Modified: "~",
+}
+
+// printChanges prints one line per change, the modified fields are printed as old -> new.
+func printChanges(w io.Writer, changes []deprecation.Change) {
+	for _, c := range changes {
+		v := c.Version()
+		var details []string
+		switch c.Type {
+		case deprecation.Modified:
+			for _, f := range versionFields {
+				if o, n := f.value(c.Old), f.value(c.New); o != n {
+					details = append(details, fmt.Sprintf("%s %s -> %s", f.name, quoteEmpty(o), quoteEmpty(n)))
+				}
+			}
+		default:
+			for _, f := range versionFields[:3] {
+				details = append(details, fmt.Sprintf("%s %s", f.name, quoteEmpty(f.value(v))))
+			}
+		}
+		component := ""
+		if v.Component != "" && v.Component != deprecation.KubernetesComponent {
+			component = " (" + v.Component + ")"
+		}
+		fmt.Fprintf(w, "%s %s %s%s: %s\n", changeSymbols[c.Type], v.APIVersion, v.Kind, component, strings.Join(details, ", "))
+	}
+}
+
+// printFieldChanges prints one line per changed field or annotation, like printChanges.
+func printFieldChanges(w io.Writer, changes []deprecation.FieldChange) {
+	for _, c := range changes {
+		f := c.FieldVersion()
+		var details []string
+		for _, field := range fieldVersionFields {
+			o, n := "", ""
+			if c.Old != nil {
+				o = field.value(c.Old)
+			}
+			if c.New != nil {
+				n = field.value(c.New)
+			}
+			switch {
+			case c.Type != deprecation.Modified && field.name != "replacement":
+				details = append(details, fmt.Sprintf("%s %s", field.name, quoteEmpty(field.value(f))))
+			case c.Type == deprecation.Modified && o != n:
+				details = append(details, fmt.Sprintf("%s %s -> %s", field.name, quoteEmpty(o), quoteEmpty(n)))
+			}
+		}
+		version := f.APIVersion
+		if version == "" {
+			version = "*"
+		}
+		component := ""
+		if f.Component != "" && f.Component != deprecation.KubernetesComponent {
+			component = " (" + f.Component + ")"
+		}
+		fmt.Fprintf(w, "%s %s %s %s%s: %s\n", changeSymbols[c.Type], version, f.Kind, f.Name(), component, strings.Join(details, ", "))
+	}
+}
+
+// fieldVersionField is a field of a deprecated field or annotation compared by the diffs.
+type fieldVersionField struct {
+	name  string
+	value func(f *deprecation.FieldVersion) string
+}
+
+// fieldVersionFields are the fields of a deprecated field or annotation compared by the diffs
+var fieldVersionFields = []fieldVersionField{
+	{name: "deprecatedInVersion", value: func(f *deprecation.FieldVersion) string { return f.DeprecatedInVersion }},
+	{name: "removedInVersion", value: func(f *deprecation.FieldVersion) string { return f.RemovedInVersion }},
+	{name: "replacement", value: func(f *deprecation.FieldVersion) string { return f.Replacement }},
+}
+
+// versionField is a field of a deprecated API version compared by the diffs.
+type versionField struct {
+	name  string
+	value func(v *deprecation.Version) string
+}
+
+// versionFields are the fields of a deprecated API version compared by the diffs
+var versionFields = []versionField{
+	{name: "introducedInVersion", value: func(v *deprecation.Version) string { return v.IntroducedInVersion }},
+	{name: "deprecatedInVersion", value: func(v *deprecation.Version) string { return v.DeprecatedInVersion }},
+	{name: "removedInVersion", value: func(v *deprecation.Version) string { return v.RemovedInVersion }},
+	{name: "replacementApi", value: func(v *deprecation.Version) string { return v.ReplacementAPI }},
+	{name: "severity", value: func(v *deprecation.Version) string { return v.Severity }},
+	{name: "migrationNote", value: func(v *deprecation.Version) string { return v.MigrationNote }},
+	{name: "migrationGuide", value: func(v *deprecation.Version) string { return v.MigrationGuide }},
+	{name: "fieldChanges", value: func(v *deprecation.Version) string { return strings.Join(v.FieldChanges, "; ") }},
+}
+
+// quoteEmpty returns "" for an empty value, so it's visible.
+func quoteEmpty(value string) string {
+	if value == "" {
+		return `""`
+	}
+	return value
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/wayfair-incubator/k8s-used-api-versions/pkg/deprecation"
+)
+
+const (
+	plutoFormat  = "pluto"
+	kubentFormat = "kubent"
+)
+
+// stringList is a flag which can be set more than once.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// runImport converts the files of another tool to a versions file.
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	format := fs.String("format", plutoFormat, "The format of the files, pluto for a Pluto versions file "+
+		"or kubent for a kube-no-trouble Rego rule.")
+	output := fs.String("o", "", "The imported versions file, the standard output when it's not set.")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: dataset import [flags] file...\n\n"+
+			"The files are merged in order, the API versions of a file override the ones of the files before it.\n\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		return fmt.Errorf("at least one file is required")
+	}
+
+	sources := make([]deprecation.Source, 0, fs.NArg())
+	for _, path := range fs.Args() {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var versions *deprecation.Versions
+		switch *format {
+		case plutoFormat:
+			versions, _, err = deprecation.ParsePluto(content)
+		case kubentFormat:
+			versions, err = deprecation.ParseKubentRego(content)
+		default:
+			return fmt.Errorf("unknown format %q", *format)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		sources = append(sources, deprecation.Source{Name: path, Versions: versions})
+	}
+	dataset, conflicts, err := deprecation.Merge(sources...)
+	if err != nil {
+		return err
+	}
+	for _, c := range conflicts {
+		fmt.Fprintf(os.Stderr, "conflict: %s\n", c)
+	}
+	return writeVersions(*output, dataset.Versions())
+}
+
+// runExport converts a versions file to the format of another tool.
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", plutoFormat, "The format of the exported file, only pluto is supported.")
+	input := fs.String("i", "", "The versions file to export, the versions file compiled into the binary when it's not set.")
+	output := fs.String("o", "", "The exported file, the standard output when it's not set.")
+	var targetVersions stringList
+	fs.Var(&targetVersions, "target-version", "The target version of a component as name=version, such as k8s=v1.25.0. "+
+		"It can be set more than once.")
+	_ = fs.Parse(args)
+	if *format != plutoFormat {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	versions := deprecation.Default().Versions()
+	if *input != "" {
+		content, err := ioutil.ReadFile(*input)
+		if err != nil {
+			return err
+		}
+		if versions, err = deprecation.ParseVersions(content); err != nil {
+			return err
+		}
+	}
+	targets, err := parseComponentVersions(targetVersions)
+	if err != nil {
+		return err
+	}
+
+	content, err := deprecation.MarshalPluto(versions, targets)
+	if err != nil {
+		return err
+	}
+	return writeFile(*output, content)
+}
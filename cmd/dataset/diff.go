@@ -0,0 +1,333 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	yamlv3 "gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiversionv1beta1 "github.com/wayfair-incubator/k8s-used-api-versions/api/v1beta1"
+	"github.com/wayfair-incubator/k8s-used-api-versions/controllers"
+	"github.com/wayfair-incubator/k8s-used-api-versions/pkg/deprecation"
+)
+
+// runDiff prints the rules which changed between two versions files, and the UsedApiVersions whose status changes.
+func runDiff(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	manifests := fs.String("manifests", "", "A directory of UsedApiVersions manifests whose status changes are printed.")
+	cluster := fs.Bool("cluster", false, "Print the status changes of the UsedApiVersions of the cluster.")
+	kubeconfig := fs.String("kubeconfig", "", "The kubeconfig of the cluster, the in-cluster config or $HOME/.kube/config when it's not set.")
+	lookAhead := fs.Int("look-ahead-releases", controllers.DefaultLookAheadReleases, "The number of releases ahead the removals "+
+		"are looked for, unless a UsedApiVersions sets its own.")
+	var targetVersions stringList
+	fs.Var(&targetVersions, "target-version", "A target Kubernetes version the API versions are also checked against, "+
+		"unless a UsedApiVersions sets its own. It can be set more than once.")
+	calendarFile := fs.String("calendar-file", "", "A release calendar file adding dates to the upstream release calendar, "+
+		"such as the planned upgrade dates.")
+	inferLifecycle := fs.Bool("infer-lifecycle", false, "Infer the deprecation and removal of the alpha and beta Kubernetes API "+
+		"versions which are not in the versions files from the Kubernetes deprecation policy.")
+	var componentVersions stringList
+	fs.Var(&componentVersions, "component-version", "The installed version of a component as name=version, such as k8s=v1.22.0. "+
+		"It can be set more than once. The Kubernetes version of the cluster is used when k8s is not set.")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: dataset diff [flags] old.yaml new.yaml\n\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return fmt.Errorf("expected the old and the new versions files")
+	}
+	if *manifests != "" && *cluster {
+		return fmt.Errorf("--manifests and --cluster are exclusive")
+	}
+
+	from, err := readVersionsFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	to, err := readVersionsFile(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	printChanges(os.Stdout, deprecation.Diff(from, to))
+	printFieldChanges(os.Stdout, deprecation.DiffFields(from, to))
+	if *manifests == "" && !*cluster {
+		return nil
+	}
+
+	versions, err := parseComponentVersions(componentVersions)
+	if err != nil {
+		return err
+	}
+	var items []apiversionv1beta1.UsedApiVersions
+	if *cluster {
+		config, err := clusterConfig(*kubeconfig)
+		if err != nil {
+			return err
+		}
+		if items, err = clusterUsedApiVersions(config); err != nil {
+			return err
+		}
+		if _, ok := versions[deprecation.KubernetesComponent]; !ok {
+			k8sVersion, err := (&controllers.KubernetesVersionDetector{Discovery: discovery.NewDiscoveryClientForConfigOrDie(config)}).
+				DetectVersion(context.Background())
+			if err != nil {
+				return err
+			}
+			versions[deprecation.KubernetesComponent] = k8sVersion
+		}
+	} else if items, err = localUsedApiVersions(*manifests); err != nil {
+		return err
+	}
+	if _, ok := versions[deprecation.KubernetesComponent]; !ok {
+		return fmt.Errorf("--component-version k8s=<version> is required to check local manifests")
+	}
+
+	oldDataset, err := deprecation.NewDataset(from)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fs.Arg(0), err)
+	}
+	newDataset, err := deprecation.NewDataset(to)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fs.Arg(1), err)
+	}
+	if *inferLifecycle {
+		oldDataset, newDataset = oldDataset.WithInferredLifecycle(), newDataset.WithInferredLifecycle()
+	}
+	calendar := deprecation.DefaultCalendar()
+	if *calendarFile != "" {
+		planned, err := deprecation.LoadCalendar(*calendarFile)
+		if err != nil {
+			return err
+		}
+		calendar = deprecation.MergeCalendars(calendar, planned)
+	}
+	defaults := controllers.StatusDefaults{LookAheadReleases: *lookAhead, TargetVersions: targetVersions, Calendar: calendar,
+		// both datasets count the days until the removals from the same time
+		Now: time.Now()}
+	fmt.Println()
+	changed := 0
+	for i := range items {
+		lines, err := statusChanges(&items[i], oldDataset, newDataset, versions, defaults)
+		if err != nil {
+			return fmt.Errorf("%s: %w", objectName(&items[i]), err)
+		}
+		for _, line := range lines {
+			fmt.Printf("%s: %s\n", objectName(&items[i]), line)
+		}
+		if len(lines) > 0 {
+			changed++
+		}
+	}
+	fmt.Printf("%d of %d UsedApiVersions change status\n", changed, len(items))
+	return nil
+}
+
+// readVersionsFile reads a versions file.
+func readVersionsFile(file string) (*deprecation.Versions, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	v, err := deprecation.ParseVersions(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return v, nil
+}
+
+// parseComponentVersions parses the name=version component versions.
+func parseComponentVersions(values []string) (deprecation.ComponentVersions, error) {
+	versions := deprecation.ComponentVersions{}
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid component version %q, expected name=version", value)
+		}
+		versions[parts[0]] = parts[1]
+	}
+	return versions, nil
+}
+
+// statusChanges returns one line per API version of the UsedApiVersions whose status changes between the datasets,
+// the changed fields are printed as old -> new.
+func statusChanges(u *apiversionv1beta1.UsedApiVersions, from, to *deprecation.Dataset, versions deprecation.ComponentVersions, defaults controllers.StatusDefaults) ([]string, error) {
+	oldStatus, err := controllers.StatusOf(from, u, versions, defaults)
+	if err != nil {
+		return nil, err
+	}
+	newStatus, err := controllers.StatusOf(to, u, versions, defaults)
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	for i := range newStatus.ApiVersionsStatus {
+		o, n := reflect.ValueOf(oldStatus.ApiVersionsStatus[i]), reflect.ValueOf(newStatus.ApiVersionsStatus[i])
+		var details []string
+		for f := 0; f < n.NumField(); f++ {
+			name := strings.Split(n.Type().Field(f).Tag.Get("json"), ",")[0]
+			// the source is the versions file, the same for both datasets
+			if name == "source" {
+				continue
+			}
+			if reflect.DeepEqual(o.Field(f).Interface(), n.Field(f).Interface()) {
+				continue
+			}
+			details = append(details, fmt.Sprintf("%s %s -> %s", name, quoteEmpty(fieldValue(o.Field(f))), quoteEmpty(fieldValue(n.Field(f)))))
+		}
+		if len(details) > 0 {
+			s := newStatus.ApiVersionsStatus[i]
+			lines = append(lines, fmt.Sprintf("%s %s: %s", s.APIVersion, s.Kind, strings.Join(details, ", ")))
+		}
+	}
+	for i, target := range newStatus.TargetVersionsStatus {
+		oldTarget := oldStatus.TargetVersionsStatus[i]
+		if target.Error != "" || oldTarget.Error != "" {
+			continue
+		}
+		for j, n := range target.ApiVersions {
+			o := oldTarget.ApiVersions[j]
+			var details []string
+			if o.Deprecated != n.Deprecated {
+				details = append(details, fmt.Sprintf("deprecated %t -> %t", o.Deprecated, n.Deprecated))
+			}
+			if o.Removed != n.Removed {
+				details = append(details, fmt.Sprintf("removed %t -> %t", o.Removed, n.Removed))
+			}
+			if o.NotYetAvailable != n.NotYetAvailable {
+				details = append(details, fmt.Sprintf("notYetAvailable %t -> %t", o.NotYetAvailable, n.NotYetAvailable))
+			}
+			if len(details) > 0 {
+				lines = append(lines, fmt.Sprintf("%s %s in %s: %s", n.APIVersion, n.Kind, target.Version, strings.Join(details, ", ")))
+			}
+		}
+	}
+	return lines, nil
+}
+
+// fieldValue formats the value of a status field, a nil pointer is empty. The lists are formatted as JSON so the
+// pointers they hold are printed by value.
+func fieldValue(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Struct {
+		if content, err := json.Marshal(v.Interface()); err == nil {
+			return string(content)
+		}
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// localUsedApiVersions reads the UsedApiVersions manifests of the YAML and JSON files of a directory,
+// the other objects are skipped.
+func localUsedApiVersions(dir string) ([]apiversionv1beta1.UsedApiVersions, error) {
+	var items []apiversionv1beta1.UsedApiVersions
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		switch filepath.Ext(path) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		decoder := yamlv3.NewDecoder(bytes.NewReader(content))
+		for {
+			var doc map[string]interface{}
+			if err := decoder.Decode(&doc); errors.Is(err, io.EOF) {
+				return nil
+			} else if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			if doc["kind"] != "UsedApiVersions" {
+				continue
+			}
+			// the documents are converted to JSON to be decoded with the json tags of the API types
+			raw, err := json.Marshal(doc)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			var u apiversionv1beta1.UsedApiVersions
+			if err := json.Unmarshal(raw, &u); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			items = append(items, u)
+		}
+	})
+	return items, err
+}
+
+// clusterConfig returns the config of the kubeconfig, the default config when it's not set.
+func clusterConfig(kubeconfig string) (*rest.Config, error) {
+	if kubeconfig != "" {
+		return clientcmd.BuildConfigFromFlags("", kubeconfig)
+	}
+	return ctrl.GetConfig()
+}
+
+// clusterUsedApiVersions lists the UsedApiVersions of all the namespaces of the cluster.
+func clusterUsedApiVersions(config *rest.Config) ([]apiversionv1beta1.UsedApiVersions, error) {
+	scheme := runtime.NewScheme()
+	if err := apiversionv1beta1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	c, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, err
+	}
+	var list apiversionv1beta1.UsedApiVersionsList
+	if err := c.List(context.Background(), &list); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// objectName returns the namespace/name of the UsedApiVersions.
+func objectName(u *apiversionv1beta1.UsedApiVersions) string {
+	if u.Namespace == "" {
+		return u.Name
+	}
+	return u.Namespace + "/" + u.Name
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	apiversionv1beta1 "github.com/wayfair-incubator/k8s-used-api-versions/api/v1beta1"
+	"github.com/wayfair-incubator/k8s-used-api-versions/controllers"
+	"github.com/wayfair-incubator/k8s-used-api-versions/pkg/deprecation"
+)
+
+func TestStatusChanges(t *testing.T) {
+	u := &apiversionv1beta1.UsedApiVersions{Spec: apiversionv1beta1.UsedApiVersionsSpec{UsedApiVersions: []apiversionv1beta1.APIVersionMeta{
+		{Kind: "Ingress", APIVersion: "networking.k8s.io/v1beta1", Fields: []string{"spec.backend"},
+			Annotations: []string{"kubernetes.io/ingress.class"}},
+		{Kind: "Deployment", APIVersion: "apps/v1", Annotations: []string{"seccomp.security.alpha.kubernetes.io/pod"}},
+	}}}
+	versions := deprecation.ComponentVersions{deprecation.KubernetesComponent: "v1.25.0"}
+	defaults := controllers.StatusDefaults{LookAheadReleases: controllers.DefaultLookAheadReleases,
+		TargetVersions: []string{"v1.27.0"}, Calendar: deprecation.DefaultCalendar(), Now: time.Now()}
+
+	lines, err := statusChanges(u, deprecation.Default(), deprecation.Default(), versions, defaults)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 0 {
+		t.Fatalf("Expected no changes diffing a dataset against itself, got: %v", lines)
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join("..", "..", "pkg", "deprecation", "versions.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := deprecation.ParseVersions(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range v.DeprecatedFields {
+		if f.Annotation == "seccomp.security.alpha.kubernetes.io/pod" {
+			f.RemovedInVersion = "v1.28.0"
+		}
+	}
+	changed, err := deprecation.NewDataset(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines, err = statusChanges(u, deprecation.Default(), changed, versions, defaults)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 1 || !strings.HasPrefix(lines[0], "apps/v1 Deployment: fieldsStatus [") || strings.Contains(lines[0], "0x") {
+		t.Fatalf("Expected the changed fields status to be printed by value, got: %v", lines)
+	}
+}
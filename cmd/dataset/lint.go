@@ -0,0 +1,93 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/wayfair-incubator/k8s-used-api-versions/pkg/deprecation"
+)
+
+// defaultFileName is the name of the versions file compiled into the binary in the findings
+const defaultFileName = "<default>"
+
+// fileFinding is a finding of a versions file.
+type fileFinding struct {
+	File string `json:"file"`
+	deprecation.Finding
+}
+
+// runLint validates versions files.
+func runLint(args []string) error {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	format := fs.String("output", "text", "The format of the findings, text or json.")
+	strict := fs.Bool("strict", false, "Fail on warnings as well as errors.")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: dataset lint [flags] [file...]\n\n"+
+			"The versions file compiled into the binary is validated when no file is set.\n\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("unknown output format %q", *format)
+	}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{defaultFileName}
+	}
+	findings := []fileFinding{}
+	for _, file := range files {
+		content := deprecation.DefaultVersionsFile()
+		if file != defaultFileName {
+			var err error
+			if content, err = ioutil.ReadFile(file); err != nil {
+				return err
+			}
+		}
+		for _, f := range deprecation.Validate(content) {
+			findings = append(findings, fileFinding{File: file, Finding: f})
+		}
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(findings); err != nil {
+			return err
+		}
+	} else {
+		for _, f := range findings {
+			fmt.Printf("%s:%d: %s: %s (%s)\n", f.File, f.Line, f.Severity, f.Message, f.Check)
+		}
+	}
+
+	failed := 0
+	for _, f := range findings {
+		if f.Severity == deprecation.SeverityError || *strict {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d problems found", failed)
+	}
+	return nil
+}
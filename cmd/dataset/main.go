@@ -0,0 +1,121 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The dataset command maintains the versions files of the deprecated API versions.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/wayfair-incubator/k8s-used-api-versions/pkg/deprecation"
+)
+
+// command is a dataset subcommand.
+type command struct {
+	// summary is the one line description of the command
+	summary string
+	run     func(args []string) error
+}
+
+// commands are the dataset subcommands by name.
+var commands = map[string]command{
+	"diff": {
+		summary: "Print the rules which changed between two versions files and the UsedApiVersions whose status changes",
+		run:     runDiff,
+	},
+	"lint": {
+		summary: "Validate versions files",
+		run:     runLint,
+	},
+	"markers": {
+		summary: "Generate a versions file from the prerelease-lifecycle-gen markers of a k8s.io/api checkout",
+		run:     runMarkers,
+	},
+	"import": {
+		summary: "Convert Pluto versions files or kube-no-trouble Rego rules to a versions file",
+		run:     runImport,
+	},
+	"export": {
+		summary: "Convert a versions file to a Pluto versions file",
+		run:     runExport,
+	},
+	"schema": {
+		summary: "Generate the JSON Schemas of the versions files and the UsedApiVersions manifests",
+		run:     runSchema,
+	},
+	"validate": {
+		summary: "Validate versions files and UsedApiVersions manifests against their JSON Schema",
+		run:     runValidate,
+	},
+	"openapi": {
+		summary: "Generate candidate deprecated API versions by comparing the OpenAPI documents of Kubernetes releases",
+		run:     runOpenAPI,
+	},
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+	if err := cmd.run(flag.Args()[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "dataset %s: %v\n", flag.Arg(0), err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: dataset <command> [flags]\n\nCommands:\n")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun 'dataset <command> -h' for the flags of a command.\n")
+}
+
+// writeVersions writes the versions file to the output file, to the standard output when it's empty.
+func writeVersions(output string, v *deprecation.Versions) error {
+	content, err := deprecation.MarshalVersions(v)
+	if err != nil {
+		return err
+	}
+	return writeFile(output, content)
+}
+
+// writeFile writes the content to the output file, to the standard output when it's empty.
+func writeFile(output string, content []byte) error {
+	if output == "" {
+		_, err := os.Stdout.Write(content)
+		return err
+	}
+	return ioutil.WriteFile(output, content, 0o644)
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/wayfair-incubator/k8s-used-api-versions/pkg/generator"
+)
+
+// runMarkers generates a versions file from the prerelease-lifecycle-gen markers.
+func runMarkers(args []string) error {
+	fs := flag.NewFlagSet("markers", flag.ExitOnError)
+	apiDir := fs.String("api-dir", "", "The k8s.io/api checkout, such as $(go env GOMODCACHE)/k8s.io/api@v0.23.0.")
+	output := fs.String("o", "", "The generated versions file, the standard output when it's not set.")
+	_ = fs.Parse(args)
+	if *apiDir == "" {
+		return fmt.Errorf("--api-dir is required")
+	}
+
+	versions, err := generator.FromLifecycleMarkers(*apiDir)
+	if err != nil {
+		return err
+	}
+	return writeVersions(*output, versions)
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	semver "github.com/hashicorp/go-version"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/wayfair-incubator/k8s-used-api-versions/pkg/deprecation"
+	"github.com/wayfair-incubator/k8s-used-api-versions/pkg/generator"
+)
+
+// runOpenAPI generates candidate deprecated API versions from the OpenAPI documents of several Kubernetes releases.
+func runOpenAPI(args []string) error {
+	fs := flag.NewFlagSet("openapi", flag.ExitOnError)
+	output := fs.String("o", "", "The generated versions file, the standard output when it's not set.")
+	compare := fs.String("compare", "", "A versions file to compare the candidates with. The differences are printed "+
+		"instead of the candidates, unless -o is set.")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: dataset openapi [flags] [release=]document...\n\n"+
+			"The documents are swagger.json or OpenAPI 3 documents of the Kubernetes API, one or more per release.\n"+
+			"The release is read from info.version, it must be set as release=document otherwise, such as v1.25.0=apps-v1.json.\n\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() < 2 {
+		return fmt.Errorf("at least two documents are required")
+	}
+
+	releases := make(map[string]*generator.Spec)
+	var specs []*generator.Spec
+	for _, arg := range fs.Args() {
+		spec, err := readSpec(arg)
+		if err != nil {
+			return err
+		}
+		release := spec.Release.String()
+		if existing, ok := releases[release]; ok {
+			existing.Merge(spec)
+			continue
+		}
+		releases[release] = spec
+		specs = append(specs, spec)
+	}
+	if len(specs) < 2 {
+		return fmt.Errorf("the documents of at least two releases are required")
+	}
+	candidates, err := generator.FromOpenAPISpecs(specs...)
+	if err != nil {
+		return err
+	}
+
+	if *compare == "" || *output != "" {
+		if err := writeVersions(*output, candidates); err != nil {
+			return err
+		}
+	}
+	if *compare != "" {
+		content, err := ioutil.ReadFile(*compare)
+		if err != nil {
+			return err
+		}
+		current, err := deprecation.ParseVersions(content)
+		if err != nil {
+			return err
+		}
+		printChanges(os.Stdout, deprecation.Diff(servedVersions(current, specs), candidates))
+	}
+	return nil
+}
+
+// readSpec reads an OpenAPI document set as [release=]path.
+func readSpec(arg string) (*generator.Spec, error) {
+	path, release := arg, ""
+	if parts := strings.SplitN(arg, "=", 2); len(parts) == 2 {
+		release, path = parts[0], parts[1]
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := generator.ParseOpenAPI(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if release != "" {
+		if spec.Release, err = semver.NewVersion(release); err != nil {
+			return nil, fmt.Errorf("invalid release of %s: %w", path, err)
+		}
+	}
+	if spec.Release == nil {
+		return nil, fmt.Errorf("the release of %s is unknown, set it as release=%s", path, path)
+	}
+	return spec, nil
+}
+
+// servedVersions returns the deprecated API versions of Kubernetes served by any of the specs,
+// the specs can't tell anything about the other API versions.
+func servedVersions(v *deprecation.Versions, specs []*generator.Spec) *deprecation.Versions {
+	served := new(deprecation.Versions)
+	for _, dep := range v.DeprecatedVersions {
+		if dep.Component != "" && dep.Component != deprecation.KubernetesComponent {
+			continue
+		}
+		if generator.Served(schema.FromAPIVersionAndKind(dep.APIVersion, dep.Kind), specs...) {
+			served.DeprecatedVersions = append(served.DeprecatedVersions, dep)
+		}
+	}
+	return served
+}
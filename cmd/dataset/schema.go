@@ -0,0 +1,126 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+
+	yamlv3 "gopkg.in/yaml.v3"
+
+	"github.com/wayfair-incubator/k8s-used-api-versions/pkg/generator"
+)
+
+// schemas are the generated JSON Schemas by name.
+var schemas = map[string]func() *generator.JSONSchema{
+	"versions":        generator.VersionsSchema,
+	"usedapiversions": generator.UsedApiVersionsSchema,
+}
+
+// runSchema writes the JSON Schemas of the versions files and the UsedApiVersions manifests.
+func runSchema(args []string) error {
+	fs := flag.NewFlagSet("schema", flag.ExitOnError)
+	output := fs.String("o", "", "The directory of the generated <name>.schema.json files, the standard output when it's not set.")
+	name := fs.String("schema", "", "Only generate a schema, versions or usedapiversions.")
+	_ = fs.Parse(args)
+
+	for _, n := range []string{"versions", "usedapiversions"} {
+		if *name != "" && *name != n {
+			continue
+		}
+		content, err := json.MarshalIndent(schemas[n](), "", "  ")
+		if err != nil {
+			return err
+		}
+		content = append(content, '\n')
+		file := ""
+		if *output != "" {
+			file = filepath.Join(*output, n+".schema.json")
+		}
+		if err := writeFile(file, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runValidate validates versions files and UsedApiVersions manifests against their JSON Schema.
+func runValidate(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	name := fs.String("schema", "", "The schema of the files, versions or usedapiversions. "+
+		"It's detected from every document when it's not set, a UsedApiVersions manifest has the UsedApiVersions kind.")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: dataset validate [flags] file...\n\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("no file to validate")
+	}
+	if _, ok := schemas[*name]; !ok && *name != "" {
+		return fmt.Errorf("unknown schema %q", *name)
+	}
+
+	invalid := 0
+	for _, file := range fs.Args() {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		decoder := yamlv3.NewDecoder(bytes.NewReader(content))
+		for doc := 1; ; doc++ {
+			var value interface{}
+			if err := decoder.Decode(&value); errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				fmt.Printf("%s: %v\n", file, err)
+				invalid++
+				break
+			}
+			if value == nil {
+				continue
+			}
+			n := *name
+			if n == "" {
+				n = detectSchema(value)
+			}
+			for _, e := range schemas[n]().Validate(value) {
+				fmt.Printf("%s: document %d: %s\n", file, doc, e)
+				invalid++
+			}
+		}
+	}
+	if invalid > 0 {
+		return fmt.Errorf("%d errors found", invalid)
+	}
+	return nil
+}
+
+// detectSchema returns the schema of a document, usedapiversions for the UsedApiVersions manifests and versions otherwise.
+func detectSchema(value interface{}) string {
+	if m, ok := value.(map[string]interface{}); ok && m["kind"] == "UsedApiVersions" {
+		return "usedapiversions"
+	}
+	return "versions"
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/wayfair-incubator/k8s-used-api-versions/pkg/deprecation"
+)
+
+// DefaultConfigMapKey is the ConfigMap key holding the versions file
+const DefaultConfigMapKey = "versions.yaml"
+
+// ConfigMapWatcher watches a single ConfigMap holding a versions file and updates its source in the store
+// when the ConfigMap changes. Only the named ConfigMap is watched, so the other ConfigMaps are not cached.
+type ConfigMapWatcher struct {
+	Clientset kubernetes.Interface
+	// ConfigMap is the namespace and name of the ConfigMap
+	ConfigMap types.NamespacedName
+	// Key is the ConfigMap key holding the versions file
+	Key string
+	// Source is the name of the dataset source loaded from the ConfigMap
+	Source string
+	// Store holds the dataset merged from the ConfigMap and the other sources
+	Store *deprecation.Store
+	Log   logr.Logger
+	// Reloaded receives an event every time the ConfigMap is reloaded, it's used to requeue all the UsedApiVersions.
+	Reloaded chan<- event.GenericEvent
+}
+
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+
+// Start watches the ConfigMap until the context is done.
+func (w *ConfigMapWatcher) Start(ctx context.Context) error {
+	lw := cache.NewListWatchFromClient(
+		w.Clientset.CoreV1().RESTClient(),
+		"configmaps",
+		w.ConfigMap.Namespace,
+		fields.OneTermEqualSelector("metadata.name", w.ConfigMap.Name),
+	)
+	_, informer := cache.NewInformer(lw, &corev1.ConfigMap{}, 0, cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			w.load(obj.(*corev1.ConfigMap))
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			w.load(obj.(*corev1.ConfigMap))
+		},
+		DeleteFunc: func(_ interface{}) {
+			w.update(nil)
+		},
+	})
+	informer.Run(ctx.Done())
+	return nil
+}
+
+// NeedLeaderElection returns false, so every replica keeps its dataset up to date.
+func (w *ConfigMapWatcher) NeedLeaderElection() bool {
+	return false
+}
+
+// load parses the versions file of the ConfigMap and updates its source in the store,
+// the current dataset is kept when it can't be parsed.
+func (w *ConfigMapWatcher) load(cm *corev1.ConfigMap) {
+	content, ok := cm.Data[w.Key]
+	if !ok {
+		w.Log.Info("The ConfigMap has no versions file key, ignoring it.", "configMap", w.ConfigMap, "key", w.Key)
+		w.update(nil)
+		return
+	}
+	versions, err := deprecation.ParseVersions([]byte(content))
+	if err != nil {
+		w.Log.Error(err, "unable to parse the ConfigMap versions file, keeping the current dataset", "configMap", w.ConfigMap)
+		return
+	}
+	w.update(versions)
+}
+
+// update replaces the ConfigMap source in the store, nil removes all its API versions.
+func (w *ConfigMapWatcher) update(versions *deprecation.Versions) {
+	if err := w.Store.Update(w.Source, versions); err != nil {
+		w.Log.Error(err, "invalid ConfigMap versions file, keeping the current dataset", "configMap", w.ConfigMap)
+		return
+	}
+	w.Log.Info("Reloaded the ConfigMap versions file.", "configMap", w.ConfigMap)
+	logDatasetUpdated(w.Log, w.Store)
+	notifyDatasetReloaded(w.Reloaded)
+}
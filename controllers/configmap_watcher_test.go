@@ -0,0 +1,37 @@
+package controllers
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestConfigMapWatcherLoad(t *testing.T) {
+	configMap := "kube-system/versions"
+	store := NewDatasetStore("", nil, configMap)
+	w := &ConfigMapWatcher{
+		ConfigMap: types.NamespacedName{Namespace: "kube-system", Name: "versions"},
+		Key:       DefaultConfigMapKey,
+		Source:    ConfigMapSource(configMap),
+		Store:     store,
+		Log:       ctrl.Log,
+	}
+
+	w.load(&corev1.ConfigMap{Data: map[string]string{DefaultConfigMapKey: reloadedVersions}})
+	got, _ := store.Get().Check("Ingress", "extensions/v1beta1", "v1.22.0")
+	if !got.Removed || got.Source != w.Source {
+		t.Fatalf("Expected the ConfigMap rule to be used, got: %+v", got)
+	}
+
+	w.load(&corev1.ConfigMap{Data: map[string]string{DefaultConfigMapKey: "deprecatedVersions: ["}})
+	if store.Get().Len() != 1 {
+		t.Fatalf("Expected an invalid ConfigMap to keep the current dataset")
+	}
+
+	w.load(&corev1.ConfigMap{})
+	if store.Get().Len() != 0 {
+		t.Fatalf("Expected a ConfigMap without the versions file key to remove its rules")
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	apiversionv1beta1 "github.com/wayfair-incubator/k8s-used-api-versions/api/v1beta1"
+	"github.com/wayfair-incubator/k8s-used-api-versions/pkg/deprecation"
+)
+
+const (
+	// DefaultSource is the name of the dataset source loaded from the versions file compiled into the binary
+	DefaultSource = "default"
+	// VersionsFileSource is the name of the dataset source loaded from the versions file
+	VersionsFileSource = "versions-file"
+	// DeprecationRulesSource is the name of the dataset source loaded from the DeprecationRule objects
+	DeprecationRulesSource = "deprecation-rules"
+)
+
+// OverrideFileSource returns the name of the dataset source loaded from an override versions file.
+func OverrideFileSource(path string) string {
+	return "override-file:" + path
+}
+
+// ConfigMapSource returns the name of the dataset source loaded from a ConfigMap.
+func ConfigMapSource(namespacedName string) string {
+	return "configmap:" + namespacedName
+}
+
+// NewDatasetStore creates the store of the dataset sources ordered by precedence, from the lowest to the highest:
+//
+//   1. the versions file if set, the versions file compiled into the binary otherwise
+//   2. the override versions files, in the provided order
+//   3. the ConfigMap, if set
+//   4. the DeprecationRule objects
+func NewDatasetStore(versionsFile string, overrideFiles []string, configMap string) *deprecation.Store {
+	sources := []string{DefaultSource}
+	if versionsFile != "" {
+		sources[0] = VersionsFileSource
+	}
+	for _, path := range overrideFiles {
+		sources = append(sources, OverrideFileSource(path))
+	}
+	if configMap != "" {
+		sources = append(sources, ConfigMapSource(configMap))
+	}
+	sources = append(sources, DeprecationRulesSource)
+	return deprecation.NewStore(sources...)
+}
+
+// NewDatasetReloadedChannel creates the channel notified every time the dataset changes.
+func NewDatasetReloadedChannel() chan event.GenericEvent {
+	return make(chan event.GenericEvent, 1)
+}
+
+// notifyDatasetReloaded notifies the channel that the dataset changed.
+// A pending event already requeues everything, so there is no need to block.
+func notifyDatasetReloaded(reloaded chan<- event.GenericEvent) {
+	if reloaded == nil {
+		return
+	}
+	select {
+	case reloaded <- event.GenericEvent{Object: &apiversionv1beta1.UsedApiVersions{}}:
+	default:
+	}
+}
+
+// logDatasetUpdated logs the revision of the current dataset and the API versions defined differently
+// by more than one source.
+func logDatasetUpdated(log logr.Logger, store *deprecation.Store) {
+	dataset := store.Get()
+	log.Info("Updated the deprecation dataset.", "revision", dataset.Revision(), "apiVersions", dataset.Len())
+	for _, c := range store.Conflicts() {
+		log.Info("Conflicting deprecation rules.", "apiVersion", c.APIVersion, "kind", c.Kind, "field", c.Field, "used", c.Used, "ignored", c.Ignored)
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	apiversionv1beta1 "github.com/wayfair-incubator/k8s-used-api-versions/api/v1beta1"
+	"github.com/wayfair-incubator/k8s-used-api-versions/pkg/deprecation"
+)
+
+// DeprecationRuleReconciler loads the DeprecationRule objects into the dataset store
+type DeprecationRuleReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+	// DatasetStore holds the dataset merged from the DeprecationRule objects and the other sources
+	DatasetStore *deprecation.Store
+	// DatasetReloaded receives an event every time the DeprecationRule objects are reloaded
+	DatasetReloaded chan<- event.GenericEvent
+}
+
+//+kubebuilder:rbac:groups=api-version.wayfair.com,resources=deprecationrules,verbs=get;list;watch
+//+kubebuilder:rbac:groups=api-version.wayfair.com,resources=deprecationrules/status,verbs=get;update;patch
+
+// DeprecationRuleValid is the type of the condition reporting whether a DeprecationRule is loaded into the dataset.
+const DeprecationRuleValid = "Valid"
+
+// Reconcile reloads all the DeprecationRule objects into the dataset store whenever one of them changes,
+// so created, updated and deleted rules are handled the same way. An invalid rule is skipped, so it doesn't
+// keep the other rules out of the dataset, and its Valid condition reports why.
+func (r *DeprecationRuleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	var rules apiversionv1beta1.DeprecationRuleList
+	if err := r.List(ctx, &rules); err != nil {
+		log.Error(err, "unable to list DeprecationRules")
+		return ctrl.Result{}, err
+	}
+
+	versions, invalid := deprecationRulesToVersions(rules.Items)
+	for name, err := range invalid {
+		log.Info("Skipping the invalid DeprecationRule.", "name", name, "reason", err.Error())
+	}
+	if err := r.DatasetStore.Update(DeprecationRulesSource, versions); err != nil {
+		// The rules are valid one by one, an error can't be fixed by a requeue.
+		log.Error(err, "invalid DeprecationRules, keeping the current dataset")
+		return ctrl.Result{}, nil
+	}
+
+	log.Info("Reloaded DeprecationRules.", "rules", len(versions.DeprecatedVersions), "invalid", len(invalid))
+	logDatasetUpdated(log, r.DatasetStore)
+	notifyDatasetReloaded(r.DatasetReloaded)
+
+	for i := range rules.Items {
+		rule := &rules.Items[i]
+		if !setValidCondition(rule, invalid[rule.Name]) {
+			continue
+		}
+		if err := r.Status().Update(ctx, rule); err != nil {
+			log.Error(err, "unable to update DeprecationRule Status", "name", rule.Name)
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// deprecationRulesToVersions converts the valid DeprecationRule objects to deprecated API versions,
+// and returns the validation error of the invalid ones by name.
+func deprecationRulesToVersions(rules []apiversionv1beta1.DeprecationRule) (*deprecation.Versions, map[string]error) {
+	versions := new(deprecation.Versions)
+	invalid := make(map[string]error)
+	for _, rule := range rules {
+		version := &deprecation.Version{
+			APIVersion:          rule.Spec.APIVersion,
+			Kind:                rule.Spec.Kind,
+			IntroducedInVersion: rule.Spec.IntroducedInVersion,
+			DeprecatedInVersion: rule.Spec.DeprecatedInVersion,
+			RemovedInVersion:    rule.Spec.RemovedInVersion,
+			ReplacementAPI:      rule.Spec.ReplacementAPI,
+			Severity:            rule.Spec.Severity,
+			MigrationNote:       rule.Spec.MigrationNote,
+			MigrationGuide:      rule.Spec.MigrationGuide,
+			FieldChanges:        rule.Spec.FieldChanges,
+			Component:           rule.Spec.Component,
+		}
+		if _, err := deprecation.NewDataset(&deprecation.Versions{DeprecatedVersions: []*deprecation.Version{version}}); err != nil {
+			invalid[rule.Name] = err
+			continue
+		}
+		versions.DeprecatedVersions = append(versions.DeprecatedVersions, version)
+	}
+	return versions, invalid
+}
+
+// setValidCondition sets the Valid condition of the rule from its validation error,
+// and returns whether the status changed.
+func setValidCondition(rule *apiversionv1beta1.DeprecationRule, err error) bool {
+	condition := metav1.Condition{
+		Type:               DeprecationRuleValid,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: rule.Generation,
+		Reason:             "Loaded",
+		Message:            "The rule is loaded into the dataset",
+	}
+	if err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Invalid"
+		condition.Message = err.Error()
+	}
+	previous := meta.FindStatusCondition(rule.Status.Conditions, DeprecationRuleValid)
+	if previous != nil && previous.Status == condition.Status && previous.ObservedGeneration == condition.ObservedGeneration &&
+		previous.Reason == condition.Reason && previous.Message == condition.Message {
+		return false
+	}
+	meta.SetStatusCondition(&rule.Status.Conditions, condition)
+	return true
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *DeprecationRuleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		// The status updates don't change the generation, so they don't reload the rules again.
+		For(&apiversionv1beta1.DeprecationRule{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiversionv1beta1 "github.com/wayfair-incubator/k8s-used-api-versions/api/v1beta1"
+	"github.com/wayfair-incubator/k8s-used-api-versions/pkg/deprecation"
+)
+
+func TestDeprecationRuleReconcileSkipsInvalidRules(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = apiversionv1beta1.AddToScheme(scheme)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&apiversionv1beta1.DeprecationRule{
+			ObjectMeta: metav1.ObjectMeta{Name: "flowcontrol-v1beta2", Generation: 1},
+			Spec: apiversionv1beta1.DeprecationRuleSpec{APIVersion: "flowcontrol.apiserver.k8s.io/v1beta2", Kind: "FlowSchema",
+				DeprecatedInVersion: "v1.26.0", RemovedInVersion: "v1.29.0", Component: deprecation.KubernetesComponent},
+		},
+		&apiversionv1beta1.DeprecationRule{
+			ObjectMeta: metav1.ObjectMeta{Name: "invalid-pattern", Generation: 2},
+			Spec: apiversionv1beta1.DeprecationRuleSpec{APIVersion: "example.com/v1beta1", Kind: "Flow[",
+				DeprecatedInVersion: "v1.26.0", Component: deprecation.KubernetesComponent},
+		},
+	).Build()
+	store := deprecation.NewStore(DeprecationRulesSource)
+	r := &DeprecationRuleReconciler{Client: c, Scheme: scheme, DatasetStore: store}
+
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.Get().Versions().DeprecatedVersions; len(got) != 1 || got[0].Kind != "FlowSchema" {
+		t.Fatalf("Expected only the valid rule to be loaded, got: %+v", got)
+	}
+
+	cases := []struct {
+		name       string
+		status     metav1.ConditionStatus
+		generation int64
+	}{
+		{"flowcontrol-v1beta2", metav1.ConditionTrue, 1},
+		{"invalid-pattern", metav1.ConditionFalse, 2},
+	}
+	for _, c := range cases {
+		var rule apiversionv1beta1.DeprecationRule
+		if err := r.Get(context.Background(), types.NamespacedName{Name: c.name}, &rule); err != nil {
+			t.Fatal(err)
+		}
+		condition := meta.FindStatusCondition(rule.Status.Conditions, DeprecationRuleValid)
+		if condition == nil || condition.Status != c.status || condition.ObservedGeneration != c.generation {
+			t.Fatalf("Unexpected Valid condition of %v: %+v", c.name, condition)
+		}
+		if setValidCondition(&rule, deprecationRuleError(rule)) {
+			t.Fatalf("Expected the Valid condition of %v not to change on the next reload", c.name)
+		}
+	}
+}
+
+// deprecationRuleError returns the validation error of the rule.
+func deprecationRuleError(rule apiversionv1beta1.DeprecationRule) error {
+	_, invalid := deprecationRulesToVersions([]apiversionv1beta1.DeprecationRule{rule})
+	return invalid[rule.Name]
+}
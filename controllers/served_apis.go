@@ -0,0 +1,80 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/discovery"
+)
+
+// ServedAPIs checks which kinds the cluster serves with the discovery client.
+// The kinds of every group version are cached for the TTL.
+type ServedAPIs struct {
+	Discovery discovery.ServerResourcesInterface
+	// TTL is how long the kinds of a group version are used before they are discovered again
+	TTL time.Duration
+
+	mu    sync.Mutex
+	cache map[string]servedKinds
+	// now returns the current time, it's replaced by the tests
+	now func() time.Time
+}
+
+// servedKinds are the kinds served by a group version.
+type servedKinds struct {
+	kinds        map[string]bool
+	discoveredAt time.Time
+}
+
+// Served checks if the cluster serves the kind in the API version. The discovery only lists the item kinds, so a list
+// kind such as "IngressList" is served with its item kind. A group version the cluster doesn't serve isn't an error,
+// the error is returned when the discovery fails.
+func (s *ServedAPIs) Served(apiVersion, kind string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cache == nil {
+		s.cache = make(map[string]servedKinds)
+	}
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+
+	cached, ok := s.cache[apiVersion]
+	if !ok || now().Sub(cached.discoveredAt) >= s.TTL {
+		resources, err := s.Discovery.ServerResourcesForGroupVersion(apiVersion)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return false, err
+		}
+		cached = servedKinds{kinds: make(map[string]bool), discoveredAt: now()}
+		if resources != nil {
+			for _, r := range resources.APIResources {
+				cached.kinds[r.Kind] = true
+			}
+		}
+		s.cache[apiVersion] = cached
+	}
+	if cached.kinds[kind] {
+		return true, nil
+	}
+	item := strings.TrimSuffix(kind, "List")
+	return item != kind && cached.kinds[item], nil
+}
@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery/fake"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	apiversionv1beta1 "github.com/wayfair-incubator/k8s-used-api-versions/api/v1beta1"
+	"github.com/wayfair-incubator/k8s-used-api-versions/pkg/deprecation"
+)
+
+// stubDiscovery serves the resources of the group versions, and counts the discoveries.
+type stubDiscovery struct {
+	fake.FakeDiscovery
+	resources   map[string][]string
+	failing     bool
+	discoveries int
+}
+
+func (d *stubDiscovery) ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error) {
+	d.discoveries++
+	if d.failing {
+		return nil, errors.New("connection refused")
+	}
+	kinds, ok := d.resources[groupVersion]
+	if !ok {
+		gv, _ := schema.ParseGroupVersion(groupVersion)
+		return nil, apierrors.NewNotFound(gv.WithResource("").GroupResource(), "")
+	}
+	list := &metav1.APIResourceList{GroupVersion: groupVersion}
+	for _, kind := range kinds {
+		list.APIResources = append(list.APIResources, metav1.APIResource{Kind: kind})
+	}
+	return list, nil
+}
+
+func TestServedAPIs(t *testing.T) {
+	now := time.Now()
+	d := &stubDiscovery{resources: map[string][]string{"networking.k8s.io/v1": {"Ingress", "NetworkPolicy"}}}
+	s := &ServedAPIs{Discovery: d, TTL: time.Minute, now: func() time.Time { return now }}
+
+	tests := []struct {
+		apiVersion string
+		kind       string
+		served     bool
+	}{
+		{apiVersion: "networking.k8s.io/v1", kind: "Ingress", served: true},
+		{apiVersion: "networking.k8s.io/v1", kind: "IngressList", served: true},
+		{apiVersion: "networking.k8s.io/v1", kind: "IngressClass"},
+		{apiVersion: "networking.k8s.io/v1", kind: "IngressClassList"},
+		{apiVersion: "flowcontrol.apiserver.k8s.io/v1", kind: "FlowSchema"},
+	}
+	for _, tt := range tests {
+		served, err := s.Served(tt.apiVersion, tt.kind)
+		if err != nil || served != tt.served {
+			t.Fatalf("%s %s: expected served %t, got %t, %v", tt.apiVersion, tt.kind, tt.served, served, err)
+		}
+	}
+	if d.discoveries != 2 {
+		t.Fatalf("Expected the kinds of every group version to be discovered once, got %d discoveries", d.discoveries)
+	}
+
+	d.resources["networking.k8s.io/v1"] = append(d.resources["networking.k8s.io/v1"], "IngressClass")
+	now = now.Add(time.Minute)
+	if served, _ := s.Served("networking.k8s.io/v1", "IngressClass"); !served {
+		t.Fatalf("Expected the kinds to be discovered again after the TTL")
+	}
+
+	d.failing = true
+	now = now.Add(time.Minute)
+	if _, err := s.Served("networking.k8s.io/v1", "Ingress"); err == nil {
+		t.Fatalf("Expected an error when the discovery fails")
+	}
+}
+
+func TestReplacementAvailable(t *testing.T) {
+	d := &stubDiscovery{resources: map[string][]string{"networking.k8s.io/v1": {"Ingress"}}}
+	r := &UsedApiVersionsReconciler{ServedAPIs: &ServedAPIs{Discovery: d, TTL: time.Minute}}
+
+	if available := r.replacementAvailable(ctrl.Log, apiversionv1beta1.APIVersionStatus{Kind: "Ingress", ReplacementAPI: "networking.k8s.io/v1"}); available == nil || !*available {
+		t.Fatalf("Expected the replacement to be available, got: %v", available)
+	}
+	if available := r.replacementAvailable(ctrl.Log, apiversionv1beta1.APIVersionStatus{Kind: "FlowSchema", ReplacementAPI: "flowcontrol.apiserver.k8s.io/v1beta3"}); available == nil || *available {
+		t.Fatalf("Expected the replacement not to be available yet, got: %v", available)
+	}
+	if available := r.replacementAvailable(ctrl.Log, apiversionv1beta1.APIVersionStatus{Kind: "Ingress", ReplacementAPI: deprecation.NotAvailable}); available != nil {
+		t.Fatalf("Expected no availability without a replacement, got: %v", *available)
+	}
+	d.failing = true
+	if available := r.replacementAvailable(ctrl.Log, apiversionv1beta1.APIVersionStatus{Kind: "Deployment", ReplacementAPI: "apps/v1"}); available != nil {
+		t.Fatalf("Expected no availability when the discovery fails, got: %v", *available)
+	}
+}
+
+func TestReplacementAvailableIn(t *testing.T) {
+	dataset, err := deprecation.NewDataset(&deprecation.Versions{DeprecatedVersions: []*deprecation.Version{
+		{APIVersion: "flowcontrol.apiserver.k8s.io/v1beta1", Kind: "FlowSchema", RemovedInVersion: "v1.26.0", ReplacementAPI: "flowcontrol.apiserver.k8s.io/v1beta2"},
+		{APIVersion: "flowcontrol.apiserver.k8s.io/v1beta2", Kind: "FlowSchema", RemovedInVersion: "v1.29.0", ReplacementAPI: "flowcontrol.apiserver.k8s.io/v1beta3"},
+		{APIVersion: "flowcontrol.apiserver.k8s.io/v1beta3", Kind: "FlowSchema", IntroducedInVersion: "v1.26.0"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	served, notServed := true, false
+	tests := []struct {
+		apiVersion string
+		target     string
+		served     *bool
+		expected   string
+	}{
+		{apiVersion: "flowcontrol.apiserver.k8s.io/v1beta1", target: "v1.26.0", served: &served, expected: "true"},
+		{apiVersion: "flowcontrol.apiserver.k8s.io/v1beta1", target: "v1.26.0", served: &notServed, expected: "nil"},
+		{apiVersion: "flowcontrol.apiserver.k8s.io/v1beta1", target: "v1.26.0", expected: "nil"},
+		{apiVersion: "flowcontrol.apiserver.k8s.io/v1beta1", target: "v1.29.0", served: &served, expected: "false"},
+		{apiVersion: "flowcontrol.apiserver.k8s.io/v1beta2", target: "v1.25.0", served: &served, expected: "false"},
+		{apiVersion: "flowcontrol.apiserver.k8s.io/v1beta2", target: "v1.26.0", served: &notServed, expected: "true"},
+	}
+	for _, tt := range tests {
+		versions := deprecation.ComponentVersions{deprecation.KubernetesComponent: tt.target}
+		deprecations, err := dataset.Check("FlowSchema", tt.apiVersion, tt.target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := "nil"
+		if available := replacementAvailableIn(dataset, deprecations, versions, tt.served); available != nil {
+			got = strconv.FormatBool(*available)
+		}
+		if got != tt.expected {
+			t.Fatalf("%s in %s: expected the replacement availability %s, got %s", tt.apiVersion, tt.target, tt.expected, got)
+		}
+	}
+}
+
+func TestServedMismatch(t *testing.T) {
+	served, notServed := true, false
+	tests := []struct {
+		checked   bool
+		available bool
+		served    *bool
+		mismatch  bool
+	}{
+		{checked: true, available: true, served: &served},
+		{checked: true, served: &notServed},
+		{checked: true, served: &served, mismatch: true},
+		{checked: true, available: true, served: &notServed, mismatch: true},
+		{checked: true},
+		{served: &served},
+		{available: true, served: &notServed},
+	}
+	for i, tt := range tests {
+		if mismatch := servedMismatch(tt.checked, tt.available, tt.served); mismatch != tt.mismatch {
+			t.Fatalf("%d: expected the mismatch %t, got %t", i, tt.mismatch, mismatch)
+		}
+	}
+}
+
+func TestServed(t *testing.T) {
+	d := &stubDiscovery{resources: map[string][]string{"networking.k8s.io/v1": {"Ingress"}}}
+	r := &UsedApiVersionsReconciler{}
+	if served := r.served(ctrl.Log, "networking.k8s.io/v1", "Ingress"); served != nil {
+		t.Fatalf("Expected nothing checked without the served APIs, got: %v", *served)
+	}
+	r.ServedAPIs = &ServedAPIs{Discovery: d, TTL: time.Minute}
+	if served := r.served(ctrl.Log, "networking.k8s.io/v1", "Ingress"); served == nil || !*served {
+		t.Fatalf("Expected the API version to be served, got: %v", served)
+	}
+	if served := r.served(ctrl.Log, "networking.k8s.io/v1", "IngressList"); served == nil || !*served {
+		t.Fatalf("Expected the list kind to be served with its item kind, got: %v", served)
+	}
+	if served := r.served(ctrl.Log, "extensions/v1beta1", "Ingress"); served == nil || *served {
+		t.Fatalf("Expected the API version not to be served, got: %v", served)
+	}
+}
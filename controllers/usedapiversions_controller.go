@@ -18,17 +18,24 @@ package controllers
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/apimachinery/pkg/runtime"
-	discovery "k8s.io/client-go/discovery"
+	"k8s.io/apimachinery/pkg/types"
 	restclient "k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	apiversionv1beta1 "github.com/wayfair-incubator/k8s-used-api-versions/api/v1beta1"
 	"github.com/wayfair-incubator/k8s-used-api-versions/pkg/deprecation"
@@ -44,23 +51,136 @@ var (
 			"used_api_versions_namespace",
 			"kind",
 			"api_version",
+			"not_yet_available",
 			"deprecated",
 			"removed",
 			"replacement_api",
 			"removed_in_version",
 			"deprecated_in_version",
+			"introduced_in_version",
 			"removed_in_next_release",
-			"removed_in_next_2_releases"},
+			"removed_in_next_2_releases",
+			"source",
+			"component",
+			"inferred",
+			"unstable",
+			"severity"},
+	)
+	deprecationRuleMigrationInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "wf_operator_deprecation_rule_migration_info",
+			Help: "The migration guidance of the deprecation rules matching the used API versions",
+		},
+		[]string{"kind",
+			"api_version",
+			"component",
+			"migration_guide"},
+	)
+	usedApiVersionsReleasesUntilRemoval = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "wf_operator_used_api_versions_releases_until_removal",
+			Help: "The number of releases until the used API versions are removed, 0 once they are removed",
+		},
+		[]string{"name",
+			"used_api_versions_namespace",
+			"kind",
+			"api_version",
+			"component",
+			"inferred"},
+	)
+	usedApiVersionsServed = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "wf_operator_used_api_versions_served",
+			Help: "Whether the cluster serves the used API versions, 1 when it does and 0 otherwise",
+		},
+		[]string{"name",
+			"used_api_versions_namespace",
+			"kind",
+			"api_version",
+			"component",
+			"served_mismatch"},
+	)
+	usedApiVersionsDaysUntilRemoval = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "wf_operator_used_api_versions_days_until_removal",
+			Help: "The number of days until the cluster is expected to run the release removing the used API versions, 0 once it does",
+		},
+		[]string{"name",
+			"used_api_versions_namespace",
+			"kind",
+			"api_version",
+			"component",
+			"removal_date",
+			"inferred"},
+	)
+	usedApiVersionsFields = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "wf_operator_used_api_versions_fields",
+			Help: "The status of the fields and annotations used by the kinds of the used API versions",
+		},
+		[]string{"name",
+			"used_api_versions_namespace",
+			"kind",
+			"api_version",
+			"field",
+			"annotation",
+			"deprecated",
+			"removed",
+			"replacement",
+			"removed_in_version",
+			"deprecated_in_version",
+			"source",
+			"component"},
+	)
+	deprecationDatasetInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "wf_operator_deprecation_dataset_info",
+			Help: "The revision of the deprecation dataset the used API versions are checked against",
+		},
+		[]string{"revision"},
 	)
 )
 
+// DefaultLookAheadReleases is the number of releases ahead the removals are looked for by default
+const DefaultLookAheadReleases = 2
+
 // UsedApiVersionsReconciler reconciles a UsedApiVersions object
 type UsedApiVersionsReconciler struct {
 	client.Client
 	Log          logr.Logger
 	Scheme       *runtime.Scheme
 	ClientConfig *restclient.Config
-	VersionsFile string
+	// DatasetStore holds the deprecated API versions dataset shared by the reconciler and the metrics
+	DatasetStore *deprecation.Store
+	// DatasetReloaded requeues all the UsedApiVersions when it receives an event, nil disables it
+	DatasetReloaded <-chan event.GenericEvent
+	// VersionDetector detects the installed versions of Kubernetes and the other components
+	VersionDetector *ComponentVersionDetector
+	// LookAheadReleases is the number of releases ahead the removals are looked for,
+	// unless a UsedApiVersions sets its own
+	LookAheadReleases int
+	// TargetVersions are the Kubernetes versions the API versions are also checked against,
+	// unless a UsedApiVersions sets its own
+	TargetVersions []string
+	// ServedAPIs checks if the API versions and their replacements are served by the cluster, nil disables it
+	ServedAPIs *ServedAPIs
+	// Calendar holds the release dates the removal dates are computed from, nil disables them
+	Calendar *deprecation.Calendar
+	// InferLifecycle infers the deprecation and removal of the alpha and beta Kubernetes API versions which are
+	// not in the dataset from the Kubernetes deprecation policy
+	InferLifecycle bool
+}
+
+// StatusDefaults are the settings of the UsedApiVersions which don't set their own.
+type StatusDefaults struct {
+	// LookAheadReleases is the number of releases ahead the removals are looked for
+	LookAheadReleases int
+	// TargetVersions are the Kubernetes versions the API versions are also checked against
+	TargetVersions []string
+	// Calendar holds the release dates the removal dates are computed from, nil disables them
+	Calendar *deprecation.Calendar
+	// Now is the time the days until the removals are counted from, the current time when it's not set
+	Now time.Time
 }
 
 // NewUsedApiVersionsReconciler creates a new UsedApiVersionsReconciler.
@@ -74,9 +194,11 @@ func NewUsedApiVersionsReconciler(
 	)
 
 	return &UsedApiVersionsReconciler{
-		Client: cli,
-		Log:    log,
-		Scheme: scheme,
+		Client:            cli,
+		Log:               log,
+		Scheme:            scheme,
+		LookAheadReleases: DefaultLookAheadReleases,
+		Calendar:          deprecation.DefaultCalendar(),
 	}
 }
 
@@ -104,16 +226,41 @@ func (r *UsedApiVersionsReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		// on deleted requests.
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
-	k8sVersion, _ := r.getKubernetesVersion(log)
+	componentVersions := r.getComponentVersions(ctx)
+	dataset := r.dataset()
+	lookAhead := lookAheadReleases(&usedApiVersions, r.LookAheadReleases)
+	now := time.Now()
 	var usedAPIStatus []apiversionv1beta1.APIVersionStatus
 	for _, apiVersionMeta := range usedApiVersions.Spec.UsedApiVersions {
-		var usedAPI apiversionv1beta1.APIVersionStatus
-		usedAPI = getUsedAPIVersionsStatus(apiVersionMeta.Kind, apiVersionMeta.APIVersion, k8sVersion, r.VersionsFile)
+		usedAPI, err := getUsedAPIVersionsStatus(dataset, apiVersionMeta, componentVersions, lookAhead, r.Calendar, now)
+		if err != nil && !isUnknownComponentVersion(err) {
+			log.Error(err, "unable to check the deprecation status", "kind", apiVersionMeta.Kind, "apiVersion", apiVersionMeta.APIVersion)
+			return ctrl.Result{}, err
+		}
+		if err != nil {
+			log.Info("Unknown installed version of the component, skipping its deprecation status.", "component", usedAPI.Component, "kind", apiVersionMeta.Kind, "apiVersion", apiVersionMeta.APIVersion,
+				"reason", err.Error())
+		}
+		usedAPI.ReplacementAvailable = r.replacementAvailable(log, usedAPI)
+		usedAPI.Served = r.served(log, usedAPI.APIVersion, usedAPI.Kind)
+		usedAPI.ServedMismatch = servedMismatch(usedAPI.ComponentVersion != deprecation.NotAvailable && !usedAPI.Inferred,
+			!usedAPI.Removed && !usedAPI.NotYetAvailable, usedAPI.Served)
+		if usedAPI.ServedMismatch {
+			log.Info("The cluster serving the API version contradicts the dataset.", "kind", usedAPI.Kind, "apiVersion", usedAPI.APIVersion,
+				"notYetAvailable", usedAPI.NotYetAvailable, "removed", usedAPI.Removed, "served", *usedAPI.Served)
+		}
 		usedAPIStatus = append(usedAPIStatus, usedAPI)
 	}
+	if missing := missingReleases(r.Calendar, componentVersions, usedAPIStatus); len(missing) > 0 {
+		log.Info("The release calendar doesn't list the Kubernetes releases, their removal dates are unknown or fall back to other releases. Update the calendar or set --calendar-file.",
+			"releases", missing)
+	}
 
 	updateFinalStatus(usedAPIStatus, &usedApiVersions)
+	usedApiVersions.Status.FinalStatus.LookAheadReleases = lookAhead
 	usedApiVersions.Status.ApiVersionsStatus = usedAPIStatus
+	usedApiVersions.Status.TargetVersionsStatus = getTargetVersionsStatus(dataset, usedApiVersions.Spec.UsedApiVersions,
+		usedAPIStatus, componentVersions, targetVersions(&usedApiVersions, r.TargetVersions))
 
 	if err := r.Status().Update(ctx, &usedApiVersions); err != nil {
 		log.Error(err, "unable to update usedApiVersions Status")
@@ -129,8 +276,16 @@ func updateFinalStatus(usedAPIStatus []apiversionv1beta1.APIVersionStatus, usedA
 	// Reset values to zero
 	usedApiVersions.Status.FinalStatus.Deprecated = 0
 	usedApiVersions.Status.FinalStatus.Removed = 0
+	usedApiVersions.Status.FinalStatus.NotYetAvailable = 0
 	usedApiVersions.Status.FinalStatus.RemovedInNextRelease = 0
 	usedApiVersions.Status.FinalStatus.RemovedInNextTwoReleases = 0
+	usedApiVersions.Status.FinalStatus.RemovedWithinLookAhead = 0
+	usedApiVersions.Status.FinalStatus.ServedMismatch = 0
+	usedApiVersions.Status.FinalStatus.Inferred = 0
+	usedApiVersions.Status.FinalStatus.Unstable = 0
+	usedApiVersions.Status.FinalStatus.DeprecatedFields = 0
+	usedApiVersions.Status.FinalStatus.RemovedFields = 0
+	usedApiVersions.Status.FinalStatus.Severity = ""
 
 	for _, s := range usedAPIStatus {
 		if s.Deprecated == true {
@@ -139,36 +294,338 @@ func updateFinalStatus(usedAPIStatus []apiversionv1beta1.APIVersionStatus, usedA
 		if s.Removed == true {
 			usedApiVersions.Status.FinalStatus.Removed += 1
 		}
+		if s.NotYetAvailable {
+			usedApiVersions.Status.FinalStatus.NotYetAvailable += 1
+		}
 		if s.RemovedInNextRelease == true {
 			usedApiVersions.Status.FinalStatus.RemovedInNextRelease += 1
 		}
 		if s.RemovedInNextTwoReleases == true {
 			usedApiVersions.Status.FinalStatus.RemovedInNextTwoReleases += 1
 		}
+		if s.RemovedWithinLookAhead {
+			usedApiVersions.Status.FinalStatus.RemovedWithinLookAhead += 1
+		}
+		if s.ServedMismatch {
+			usedApiVersions.Status.FinalStatus.ServedMismatch += 1
+		}
+		if s.Inferred {
+			usedApiVersions.Status.FinalStatus.Inferred += 1
+		}
+		if s.Unstable {
+			usedApiVersions.Status.FinalStatus.Unstable += 1
+		}
+		if s.Deprecated || s.Removed || s.RemovedWithinLookAhead {
+			usedApiVersions.Status.FinalStatus.Severity = deprecation.MaxSeverity(usedApiVersions.Status.FinalStatus.Severity, s.Severity)
+		}
+		for _, f := range s.FieldsStatus {
+			if f.Deprecated {
+				usedApiVersions.Status.FinalStatus.DeprecatedFields += 1
+			}
+			if f.Removed {
+				usedApiVersions.Status.FinalStatus.RemovedFields += 1
+			}
+		}
 	}
 }
 
-// getUsedAPIVersionsStatus returns the overall deprecation status.
-func getUsedAPIVersionsStatus(kind, apiVersion, k8sVersion, VersionsFile string) (apiVersionStatus apiversionv1beta1.APIVersionStatus) {
-	deprecations := deprecation.CheckDeprecations(kind, apiVersion, k8sVersion, VersionsFile)
-	apiVersionStatus.APIVersion = apiVersion
-	apiVersionStatus.Kind = kind
-	apiVersionStatus.Deprecated, _ = strconv.ParseBool(deprecations["deprecated"])
-	apiVersionStatus.Removed, _ = strconv.ParseBool(deprecations["removed"])
-	apiVersionStatus.DeprecatedInVersion = deprecations["deprecatedInVersion"]
-	apiVersionStatus.RemovedInVersion = deprecations["removedInVersion"]
-	apiVersionStatus.ReplacementAPI = deprecations["replacementApi"]
-	apiVersionStatus.RemovedInNextRelease, _ = strconv.ParseBool(deprecations["removedInNextRelease"])
-	apiVersionStatus.RemovedInNextTwoReleases, _ = strconv.ParseBool(deprecations["removedInNextRelease"])
+// StatusOf returns the status of the UsedApiVersions checked against the dataset and the installed versions of the
+// components, like the reconciler does with its look-ahead releases. The API versions of a component whose installed
+// version is unknown are returned without their deprecation status. The cluster isn't checked, so whether the API
+// versions and their replacements are served isn't set.
+func StatusOf(dataset *deprecation.Dataset, usedApiVersions *apiversionv1beta1.UsedApiVersions, componentVersions deprecation.ComponentVersions, defaults StatusDefaults) (apiversionv1beta1.UsedApiVersionsStatus, error) {
+	checked := usedApiVersions.DeepCopy()
+	lookAhead := lookAheadReleases(checked, defaults.LookAheadReleases)
+	now := defaults.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	var usedAPIStatus []apiversionv1beta1.APIVersionStatus
+	for _, apiVersionMeta := range checked.Spec.UsedApiVersions {
+		usedAPI, err := getUsedAPIVersionsStatus(dataset, apiVersionMeta, componentVersions, lookAhead, defaults.Calendar, now)
+		if err != nil && !isUnknownComponentVersion(err) {
+			return apiversionv1beta1.UsedApiVersionsStatus{}, err
+		}
+		usedAPIStatus = append(usedAPIStatus, usedAPI)
+	}
+	updateFinalStatus(usedAPIStatus, checked)
+	checked.Status.FinalStatus.LookAheadReleases = lookAhead
+	checked.Status.ApiVersionsStatus = usedAPIStatus
+	checked.Status.TargetVersionsStatus = getTargetVersionsStatus(dataset, checked.Spec.UsedApiVersions,
+		usedAPIStatus, componentVersions, targetVersions(checked, defaults.TargetVersions))
+	return checked.Status, nil
+}
 
-	return
+// lookAheadReleases returns the look-ahead releases of the UsedApiVersions, the default when it doesn't set them.
+func lookAheadReleases(usedApiVersions *apiversionv1beta1.UsedApiVersions, defaultLookAhead int) int {
+	if usedApiVersions.Spec.LookAheadReleases != nil {
+		return *usedApiVersions.Spec.LookAheadReleases
+	}
+	return defaultLookAhead
+}
+
+// targetVersions returns the target Kubernetes versions of the UsedApiVersions, the default when it doesn't set them.
+func targetVersions(usedApiVersions *apiversionv1beta1.UsedApiVersions, defaultTargets []string) []string {
+	if len(usedApiVersions.Spec.TargetVersions) > 0 {
+		return usedApiVersions.Spec.TargetVersions
+	}
+	return defaultTargets
+}
+
+// getTargetVersionsStatus checks the API versions against every target Kubernetes version. Only the API versions
+// served by Kubernetes are reported, the API versions of the other components don't depend on the Kubernetes version.
+// The availability of the replacements in the cluster is read from the status of the API versions, in the same order.
+func getTargetVersionsStatus(dataset *deprecation.Dataset, apiVersionMetas []apiversionv1beta1.APIVersionMeta, usedAPIStatus []apiversionv1beta1.APIVersionStatus, componentVersions deprecation.ComponentVersions, targets []string) []apiversionv1beta1.TargetVersionStatus {
+	var statuses []apiversionv1beta1.TargetVersionStatus
+	for _, target := range targets {
+		status := apiversionv1beta1.TargetVersionStatus{Version: target}
+		versions := deprecation.ComponentVersions{}
+		for component, version := range componentVersions {
+			versions[component] = version
+		}
+		versions[deprecation.KubernetesComponent] = target
+		for i, apiVersionMeta := range apiVersionMetas {
+			deprecations, err := dataset.CheckComponent(apiVersionMeta.Component, apiVersionMeta.Kind, apiVersionMeta.APIVersion, versions)
+			if deprecations != nil && deprecations.Known && deprecations.Component != deprecation.KubernetesComponent {
+				continue
+			}
+			if err != nil {
+				status = apiversionv1beta1.TargetVersionStatus{Version: target, Error: err.Error()}
+				break
+			}
+			var served *bool
+			if i < len(usedAPIStatus) {
+				served = usedAPIStatus[i].ReplacementAvailable
+			}
+			status.ApiVersions = append(status.ApiVersions, apiversionv1beta1.TargetAPIVersionStatus{
+				APIVersion:           apiVersionMeta.APIVersion,
+				Kind:                 apiVersionMeta.Kind,
+				Deprecated:           deprecations.Deprecated,
+				Removed:              deprecations.Removed,
+				NotYetAvailable:      deprecations.NotYetAvailable,
+				Inferred:             deprecations.Inferred,
+				ReplacementAvailable: replacementAvailableIn(dataset, deprecations, versions, served),
+			})
+			if deprecations.NotYetAvailable {
+				status.NotYetAvailable++
+			}
+			if deprecations.Deprecated {
+				status.Deprecated++
+			}
+			if deprecations.Removed {
+				status.Removed++
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// replacementAvailableIn returns whether the replacement of the API version is available in the versions: false when
+// the dataset removes it or introduces it later, true when the dataset introduces it already or the cluster serves it
+// and it's not removed, nil when there's no replacement or it can't be known.
+func replacementAvailableIn(dataset *deprecation.Dataset, deprecations *deprecation.Result, versions deprecation.ComponentVersions, served *bool) *bool {
+	if deprecations.Replacement == nil {
+		return nil
+	}
+	replacement, err := dataset.CheckComponent(deprecations.Component, deprecations.Replacement.Kind, deprecations.Replacement.APIVersion, versions)
+	if err == nil && (replacement.Removed || replacement.NotYetAvailable) {
+		available := false
+		return &available
+	}
+	if err == nil && replacement.IntroducedInVersion != nil {
+		available := true
+		return &available
+	}
+	if served != nil && *served {
+		return served
+	}
+	return nil
+}
+
+// replacementAvailable checks if the cluster serves the replacement of the API version, nil when there's no
+// replacement or it can't be checked.
+func (r *UsedApiVersionsReconciler) replacementAvailable(log logr.Logger, status apiversionv1beta1.APIVersionStatus) *bool {
+	if status.ReplacementAPI == deprecation.NotAvailable || status.ReplacementAPI == "" {
+		return nil
+	}
+	return r.served(log, status.ReplacementAPI, status.Kind)
+}
+
+// served checks if the cluster serves the kind in the API version, nil when it can't be checked.
+func (r *UsedApiVersionsReconciler) served(log logr.Logger, apiVersion, kind string) *bool {
+	if r.ServedAPIs == nil {
+		return nil
+	}
+	served, err := r.ServedAPIs.Served(apiVersion, kind)
+	if err != nil {
+		log.Error(err, "unable to check if the API version is served", "kind", kind, "apiVersion", apiVersion)
+		return nil
+	}
+	return &served
+}
+
+// servedMismatch checks if the cluster serving the API version contradicts the dataset, which expects the API
+// version to be available when it's introduced and not removed yet. There's no mismatch when the API version isn't
+// checked against a known rule and component version, or the cluster isn't checked.
+func servedMismatch(checked, available bool, served *bool) bool {
+	if !checked || served == nil {
+		return false
+	}
+	return *served != available
+}
+
+// missingReleases returns the Kubernetes releases the calendar doesn't list, the installed one and the ones removing
+// the used API versions which are not removed yet.
+func missingReleases(calendar *deprecation.Calendar, componentVersions deprecation.ComponentVersions, usedAPIStatus []apiversionv1beta1.APIVersionStatus) []string {
+	if calendar == nil {
+		return nil
+	}
+	var missing []string
+	seen := make(map[string]bool)
+	check := func(version string) {
+		v, err := deprecation.NormalizeVersion(version)
+		if err != nil || calendar.Lists(v) {
+			return
+		}
+		release := fmt.Sprintf("v%d.%d", v.Segments()[0], v.Segments()[1])
+		if !seen[release] {
+			seen[release] = true
+			missing = append(missing, release)
+		}
+	}
+	if version, ok := componentVersions[deprecation.KubernetesComponent]; ok {
+		check(version)
+	}
+	for _, s := range usedAPIStatus {
+		if s.Component == deprecation.KubernetesComponent && !s.Removed && s.RemovedInVersion != deprecation.NotAvailable {
+			check(s.RemovedInVersion)
+		}
+	}
+	return missing
+}
+
+// getUsedAPIVersionsStatus returns the overall deprecation status, with the removal date of the calendar.
+// When the installed version of the component is unknown, the status is returned without the
+// deprecation status together with the error.
+func getUsedAPIVersionsStatus(dataset *deprecation.Dataset, apiVersionMeta apiversionv1beta1.APIVersionMeta, componentVersions deprecation.ComponentVersions, lookAhead int, calendar *deprecation.Calendar, now time.Time) (apiVersionStatus apiversionv1beta1.APIVersionStatus, err error) {
+	deprecations, err := dataset.CheckComponent(apiVersionMeta.Component, apiVersionMeta.Kind, apiVersionMeta.APIVersion, componentVersions)
+	if err != nil && !isUnknownComponentVersion(err) {
+		return apiVersionStatus, err
+	}
+	deprecations.ScheduleRemoval(calendar, now)
+	apiVersionStatus.APIVersion = apiVersionMeta.APIVersion
+	apiVersionStatus.Kind = apiVersionMeta.Kind
+	apiVersionStatus.NotYetAvailable = deprecations.NotYetAvailable
+	apiVersionStatus.Deprecated = deprecations.Deprecated
+	apiVersionStatus.Removed = deprecations.Removed
+	apiVersionStatus.IntroducedInVersion = deprecation.FormatVersion(deprecations.IntroducedInVersion)
+	apiVersionStatus.DeprecatedInVersion = deprecation.FormatVersion(deprecations.DeprecatedInVersion)
+	apiVersionStatus.RemovedInVersion = deprecation.FormatVersion(deprecations.RemovedInVersion)
+	apiVersionStatus.Inferred = deprecations.Inferred
+	apiVersionStatus.Unstable = deprecations.Unstable
+	apiVersionStatus.ReplacementAPI = deprecations.ReplacementAPI()
+	apiVersionStatus.Severity = deprecations.Severity
+	apiVersionStatus.MigrationNote = deprecations.MigrationNote
+	apiVersionStatus.MigrationGuide = deprecations.MigrationGuide
+	apiVersionStatus.FieldChanges = deprecations.FieldChanges
+	apiVersionStatus.RemovedInNextRelease = deprecations.RemovedInNextRelease
+	apiVersionStatus.RemovedInNextTwoReleases = deprecations.RemovedInNextTwoReleases
+	apiVersionStatus.ReleasesUntilRemoval = deprecations.ReleasesUntilRemoval
+	apiVersionStatus.RemovedWithinLookAhead = deprecations.RemovedWithin(lookAhead)
+	if deprecations.RemovalDate != nil {
+		apiVersionStatus.RemovalDate = deprecations.RemovalDate.Format(deprecation.DateFormat)
+		apiVersionStatus.DaysUntilRemoval = deprecations.DaysUntilRemoval
+	}
+	apiVersionStatus.Source = deprecations.Source
+	apiVersionStatus.Component = deprecations.Component
+	apiVersionStatus.ComponentVersion = deprecation.FormatVersion(deprecations.ComponentVersion)
+
+	fields, fieldsErr := checkFields(dataset, apiVersionMeta, componentVersions)
+	if fieldsErr != nil && !isUnknownComponentVersion(fieldsErr) {
+		return apiVersionStatus, fieldsErr
+	}
+	for _, f := range fields {
+		apiVersionStatus.FieldsStatus = append(apiVersionStatus.FieldsStatus, apiversionv1beta1.FieldStatus{
+			Field:                    f.Field,
+			Annotation:               f.Annotation,
+			Deprecated:               f.Deprecated,
+			Removed:                  f.Removed,
+			DeprecatedInVersion:      deprecation.FormatVersion(f.DeprecatedInVersion),
+			RemovedInVersion:         deprecation.FormatVersion(f.RemovedInVersion),
+			Replacement:              f.ReplacementName(),
+			RemovedInNextRelease:     f.RemovedInNextRelease,
+			RemovedInNextTwoReleases: f.RemovedInNextTwoReleases,
+			ReleasesUntilRemoval:     f.ReleasesUntilRemoval,
+			RemovedWithinLookAhead:   f.RemovedWithin(lookAhead),
+			Source:                   f.Source,
+		})
+	}
+	if err == nil {
+		err = fieldsErr
+	}
+
+	return apiVersionStatus, err
+}
+
+// checkFields checks the fields and then the annotations used by the kind. When the installed version of the
+// component of a field or annotation is unknown, it's returned without its status together with the error.
+func checkFields(dataset *deprecation.Dataset, apiVersionMeta apiversionv1beta1.APIVersionMeta, componentVersions deprecation.ComponentVersions) ([]*deprecation.FieldResult, error) {
+	var results []*deprecation.FieldResult
+	var unknown error
+	check := func(result *deprecation.FieldResult, err error) error {
+		if err != nil && !isUnknownComponentVersion(err) {
+			return err
+		}
+		if err != nil && unknown == nil {
+			unknown = err
+		}
+		results = append(results, result)
+		return nil
+	}
+	for _, field := range apiVersionMeta.Fields {
+		if err := check(dataset.CheckField(apiVersionMeta.Component, apiVersionMeta.Kind, apiVersionMeta.APIVersion, field, componentVersions)); err != nil {
+			return nil, err
+		}
+	}
+	for _, annotation := range apiVersionMeta.Annotations {
+		if err := check(dataset.CheckAnnotation(apiVersionMeta.Component, apiVersionMeta.Kind, apiVersionMeta.APIVersion, annotation, componentVersions)); err != nil {
+			return nil, err
+		}
+	}
+	return results, unknown
+}
+
+// isUnknownComponentVersion checks if the deprecation status can't be checked because the installed
+// version of a component other than Kubernetes is unknown. An unknown Kubernetes version is an error
+// since it's expected to be always known.
+func isUnknownComponentVersion(err error) bool {
+	var unknown *deprecation.UnknownComponentVersionError
+	return errors.As(err, &unknown) && unknown.Component != deprecation.KubernetesComponent
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *UsedApiVersionsReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&apiversionv1beta1.UsedApiVersions{}).
-		Complete(r)
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&apiversionv1beta1.UsedApiVersions{})
+	if r.DatasetReloaded != nil {
+		b = b.Watches(&source.Channel{Source: r.DatasetReloaded}, handler.EnqueueRequestsFromMapFunc(r.requeueAll))
+	}
+	return b.Complete(r)
+}
+
+// requeueAll returns a request for every UsedApiVersions, so their status reflects the current dataset.
+func (r *UsedApiVersionsReconciler) requeueAll(_ client.Object) []reconcile.Request {
+	var usedApiVersionsList apiversionv1beta1.UsedApiVersionsList
+	if err := r.Client.List(context.Background(), &usedApiVersionsList); err != nil {
+		r.Log.Error(err, "unable to list UsedApiVersions to requeue them")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(usedApiVersionsList.Items))
+	for _, u := range usedApiVersionsList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: u.Namespace, Name: u.Name}})
+	}
+	return requests
 }
 
 // updateUsedApiVersionsMetrics updates and export metrics for all the UsedApiVersions kinds.
@@ -179,42 +636,135 @@ func (r *UsedApiVersionsReconciler) updateUsedApiVersionsMetrics(ctx context.Con
 		log.Error(err, "error in collecting used apiVersions metrics")
 		return
 	}
-	k8sVersion, _ := r.getKubernetesVersion(log)
+	componentVersions := r.getComponentVersions(ctx)
+	dataset := r.dataset()
 
+	deprecationDatasetInfo.Reset()
+	deprecationDatasetInfo.With(prometheus.Labels{"revision": dataset.Revision()}).Set(1)
 	usedApiVersionsInfo.Reset()
+	deprecationRuleMigrationInfo.Reset()
+	usedApiVersionsReleasesUntilRemoval.Reset()
+	usedApiVersionsServed.Reset()
+	usedApiVersionsDaysUntilRemoval.Reset()
+	usedApiVersionsFields.Reset()
+	now := time.Now()
 	for _, u := range usedApiVersionsList.Items {
 		for _, apiVersionMeta := range u.Spec.UsedApiVersions {
-			deprecations := deprecation.CheckDeprecations(apiVersionMeta.Kind, apiVersionMeta.APIVersion, k8sVersion, "config/versions.yaml")
+			deprecations, err := dataset.CheckComponent(apiVersionMeta.Component, apiVersionMeta.Kind, apiVersionMeta.APIVersion, componentVersions)
+			if err != nil && !isUnknownComponentVersion(err) {
+				log.Error(err, "unable to check the deprecation status", "kind", apiVersionMeta.Kind, "apiVersion", apiVersionMeta.APIVersion)
+				continue
+			}
 			usedApiVersionsInfo.With(prometheus.Labels{
 				"name":                        u.Name,
 				"used_api_versions_namespace": u.Namespace,
 				"kind":                        apiVersionMeta.Kind,
 				"api_version":                 apiVersionMeta.APIVersion,
-				"deprecated":                  deprecations["deprecated"],
-				"removed":                     deprecations["removed"],
-				"replacement_api":             deprecations["replacementApi"],
-				"removed_in_version":          deprecations["removedInVersion"],
-				"deprecated_in_version":       deprecations["deprecatedInVersion"],
-				"removed_in_next_release":     deprecations["removedInNextRelease"],
-				"removed_in_next_2_releases":  deprecations["removedInNextTwoReleases"],
+				"not_yet_available":           strconv.FormatBool(deprecations.NotYetAvailable),
+				"deprecated":                  strconv.FormatBool(deprecations.Deprecated),
+				"removed":                     strconv.FormatBool(deprecations.Removed),
+				"replacement_api":             deprecations.ReplacementAPI(),
+				"removed_in_version":          deprecation.FormatVersion(deprecations.RemovedInVersion),
+				"deprecated_in_version":       deprecation.FormatVersion(deprecations.DeprecatedInVersion),
+				"introduced_in_version":       deprecation.FormatVersion(deprecations.IntroducedInVersion),
+				"removed_in_next_release":     strconv.FormatBool(deprecations.RemovedInNextRelease),
+				"removed_in_next_2_releases":  strconv.FormatBool(deprecations.RemovedInNextTwoReleases),
+				"source":                      deprecations.Source,
+				"component":                   deprecations.Component,
+				"inferred":                    strconv.FormatBool(deprecations.Inferred),
+				"unstable":                    strconv.FormatBool(deprecations.Unstable),
+				"severity":                    deprecations.Severity,
 			}).Set(1)
+			if deprecations.MigrationGuide != "" {
+				deprecationRuleMigrationInfo.With(prometheus.Labels{
+					"kind":            apiVersionMeta.Kind,
+					"api_version":     apiVersionMeta.APIVersion,
+					"component":       deprecations.Component,
+					"migration_guide": deprecations.MigrationGuide,
+				}).Set(1)
+			}
+			if deprecations.ReleasesUntilRemoval != nil {
+				usedApiVersionsReleasesUntilRemoval.With(prometheus.Labels{
+					"name":                        u.Name,
+					"used_api_versions_namespace": u.Namespace,
+					"kind":                        apiVersionMeta.Kind,
+					"api_version":                 apiVersionMeta.APIVersion,
+					"component":                   deprecations.Component,
+					"inferred":                    strconv.FormatBool(deprecations.Inferred),
+				}).Set(float64(*deprecations.ReleasesUntilRemoval))
+			}
+			deprecations.ScheduleRemoval(r.Calendar, now)
+			if deprecations.RemovalDate != nil {
+				usedApiVersionsDaysUntilRemoval.With(prometheus.Labels{
+					"name":                        u.Name,
+					"used_api_versions_namespace": u.Namespace,
+					"kind":                        apiVersionMeta.Kind,
+					"api_version":                 apiVersionMeta.APIVersion,
+					"component":                   deprecations.Component,
+					"removal_date":                deprecations.RemovalDate.Format(deprecation.DateFormat),
+					"inferred":                    strconv.FormatBool(deprecations.Inferred),
+				}).Set(float64(*deprecations.DaysUntilRemoval))
+			}
+			if served := r.served(log, apiVersionMeta.APIVersion, apiVersionMeta.Kind); served != nil {
+				mismatch := servedMismatch(deprecations.ComponentVersion != nil && !deprecations.Inferred, !deprecations.Removed && !deprecations.NotYetAvailable, served)
+				value := 0.0
+				if *served {
+					value = 1
+				}
+				usedApiVersionsServed.With(prometheus.Labels{
+					"name":                        u.Name,
+					"used_api_versions_namespace": u.Namespace,
+					"kind":                        apiVersionMeta.Kind,
+					"api_version":                 apiVersionMeta.APIVersion,
+					"component":                   deprecations.Component,
+					"served_mismatch":             strconv.FormatBool(mismatch),
+				}).Set(value)
+			}
+			fields, err := checkFields(dataset, apiVersionMeta, componentVersions)
+			if err != nil && !isUnknownComponentVersion(err) {
+				log.Error(err, "unable to check the deprecation status of the fields", "kind", apiVersionMeta.Kind, "apiVersion", apiVersionMeta.APIVersion)
+				continue
+			}
+			for _, f := range fields {
+				usedApiVersionsFields.With(prometheus.Labels{
+					"name":                        u.Name,
+					"used_api_versions_namespace": u.Namespace,
+					"kind":                        apiVersionMeta.Kind,
+					"api_version":                 apiVersionMeta.APIVersion,
+					"field":                       f.Field,
+					"annotation":                  f.Annotation,
+					"deprecated":                  strconv.FormatBool(f.Deprecated),
+					"removed":                     strconv.FormatBool(f.Removed),
+					"replacement":                 f.ReplacementName(),
+					"removed_in_version":          deprecation.FormatVersion(f.RemovedInVersion),
+					"deprecated_in_version":       deprecation.FormatVersion(f.DeprecatedInVersion),
+					"source":                      f.Source,
+					"component":                   f.Component,
+				}).Set(1)
+			}
 		}
 	}
 	log.Info("Updated used apiVersions metrics.")
 }
 
-func (r *UsedApiVersionsReconciler) getKubernetesVersion(log logr.Logger) (string, error) {
-	discoveryClient, _ := discovery.NewDiscoveryClientForConfig(r.ClientConfig)
-	kubeVersion, err := discoveryClient.ServerVersion()
+// dataset returns the current dataset, which infers the lifecycle of the pre-GA Kubernetes API versions when enabled.
+func (r *UsedApiVersionsReconciler) dataset() *deprecation.Dataset {
+	dataset := r.DatasetStore.Get()
+	if r.InferLifecycle {
+		return dataset.WithInferredLifecycle()
+	}
+	return dataset
+}
 
-	if err != nil {
-		log.Error(err, "failed to get Kubernetes server version.")
-		return "", err
+// getComponentVersions returns the installed versions of Kubernetes and the other components.
+func (r *UsedApiVersionsReconciler) getComponentVersions(ctx context.Context) deprecation.ComponentVersions {
+	if r.VersionDetector == nil {
+		return deprecation.ComponentVersions{}
 	}
-	return kubeVersion.String(), nil
+	return r.VersionDetector.Versions(ctx)
 }
 
 func init() {
 	// Register custom metrics with the global prometheus registry
-	metrics.Registry.MustRegister(usedApiVersionsInfo)
+	metrics.Registry.MustRegister(usedApiVersionsInfo, usedApiVersionsReleasesUntilRemoval, usedApiVersionsServed, usedApiVersionsDaysUntilRemoval, usedApiVersionsFields, deprecationRuleMigrationInfo, deprecationDatasetInfo)
 }
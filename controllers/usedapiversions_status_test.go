@@ -0,0 +1,360 @@
+package controllers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	apiversionv1beta1 "github.com/wayfair-incubator/k8s-used-api-versions/api/v1beta1"
+	"github.com/wayfair-incubator/k8s-used-api-versions/pkg/deprecation"
+)
+
+func TestGetUsedAPIVersionsStatus(t *testing.T) {
+	dataset := deprecation.Default()
+	versions := deprecation.ComponentVersions{deprecation.KubernetesComponent: "v1.21.0"}
+
+	got, err := getUsedAPIVersionsStatus(dataset, apiversionv1beta1.APIVersionMeta{Kind: "Ingress", APIVersion: "extensions/v1beta1"}, versions, DefaultLookAheadReleases, nil, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := apiversionv1beta1.APIVersionStatus{
+		APIVersion:          "extensions/v1beta1",
+		Kind:                "Ingress",
+		Deprecated:          true,
+		IntroducedInVersion: deprecation.NotAvailable,
+		DeprecatedInVersion: "v1.14.0",
+		RemovedInVersion:    "v1.22.0",
+		ReplacementAPI:      "networking.k8s.io/v1",
+		Severity:            deprecation.RuleSeverityCritical,
+		MigrationNote:       "Change the apiVersion to networking.k8s.io/v1 and update the backends to the service format",
+		MigrationGuide:      "https://kubernetes.io/docs/reference/using-api/deprecation-guide/#ingress-v122",
+		FieldChanges: []string{
+			"spec.backend is renamed to spec.defaultBackend",
+			"the backend serviceName is renamed to service.name",
+			"the backend servicePort is renamed to service.port.number for numeric ports and service.port.name for named ports",
+			"pathType is required for each path",
+		},
+		RemovedInNextRelease:     true,
+		RemovedInNextTwoReleases: true,
+		ReleasesUntilRemoval:     intPtr(1),
+		RemovedWithinLookAhead:   true,
+		Component:                "k8s",
+		ComponentVersion:         "v1.21.0",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Status: %+v doesn't match the expected status: %+v", got, expected)
+	}
+
+	// The installed version of cert-manager is not configured, so only its rule is reported.
+	got, err = getUsedAPIVersionsStatus(dataset, apiversionv1beta1.APIVersionMeta{Kind: "Certificate", APIVersion: "cert-manager.io/v1alpha2"}, versions, DefaultLookAheadReleases, nil, time.Time{})
+	if !isUnknownComponentVersion(err) {
+		t.Fatalf("Expected an unknown component version error, got: %v", err)
+	}
+	if got.Component != "cert-manager" || got.RemovedInVersion != "v1.6.0" || got.Removed || got.ComponentVersion != deprecation.NotAvailable {
+		t.Fatalf("Unexpected status of an unknown component version: %+v", got)
+	}
+
+	// A detected version which isn't a valid version, such as the latest image tag, is unknown too.
+	latest := deprecation.ComponentVersions{deprecation.KubernetesComponent: "v1.21.0", "cert-manager": "latest"}
+	got, err = getUsedAPIVersionsStatus(dataset, apiversionv1beta1.APIVersionMeta{Kind: "Certificate", APIVersion: "cert-manager.io/v1alpha2"}, latest, DefaultLookAheadReleases, nil, time.Time{})
+	if !isUnknownComponentVersion(err) || got.RemovedInVersion != "v1.6.0" || got.ComponentVersion != deprecation.NotAvailable {
+		t.Fatalf("Expected the invalid version to be unknown, got: %+v, %v", got, err)
+	}
+
+	_, err = getUsedAPIVersionsStatus(dataset, apiversionv1beta1.APIVersionMeta{Kind: "Ingress", APIVersion: "extensions/v1beta1"}, deprecation.ComponentVersions{}, DefaultLookAheadReleases, nil, time.Time{})
+	if err == nil || isUnknownComponentVersion(err) {
+		t.Fatalf("Expected an unknown Kubernetes version to be an error, got: %v", err)
+	}
+}
+
+func TestUpdateFinalStatus(t *testing.T) {
+	var u apiversionv1beta1.UsedApiVersions
+	u.Status.FinalStatus.Removed = 5
+	updateFinalStatus([]apiversionv1beta1.APIVersionStatus{
+		{Deprecated: true, RemovedInNextTwoReleases: true},
+		{Deprecated: true, Removed: true, RemovedInNextRelease: true, RemovedInNextTwoReleases: true},
+		{},
+	}, &u)
+
+	expected := apiversionv1beta1.FinalStatusResult{Deprecated: 2, Removed: 1, RemovedInNextRelease: 1, RemovedInNextTwoReleases: 2}
+	if u.Status.FinalStatus != expected {
+		t.Fatalf("Final status: %+v doesn't match the expected status: %+v", u.Status.FinalStatus, expected)
+	}
+}
+
+func TestUpdateFinalStatusSeverity(t *testing.T) {
+	var u apiversionv1beta1.UsedApiVersions
+	u.Status.FinalStatus.Severity = deprecation.RuleSeverityCritical
+	updateFinalStatus([]apiversionv1beta1.APIVersionStatus{
+		{Deprecated: true, Severity: deprecation.RuleSeverityInfo},
+		{RemovedWithinLookAhead: true, Severity: deprecation.RuleSeverityWarning},
+		// not deprecated yet, so its severity doesn't count
+		{Severity: deprecation.RuleSeverityCritical},
+	}, &u)
+	if u.Status.FinalStatus.Severity != deprecation.RuleSeverityWarning {
+		t.Fatalf("Expected the final severity to be %q, got: %q", deprecation.RuleSeverityWarning, u.Status.FinalStatus.Severity)
+	}
+}
+
+func TestStatusOf(t *testing.T) {
+	u := &apiversionv1beta1.UsedApiVersions{Spec: apiversionv1beta1.UsedApiVersionsSpec{UsedApiVersions: []apiversionv1beta1.APIVersionMeta{
+		{Kind: "Ingress", APIVersion: "extensions/v1beta1"},
+		{Kind: "Certificate", APIVersion: "cert-manager.io/v1alpha2"},
+		{Kind: "Deployment", APIVersion: "apps/v1"},
+	}}}
+	status, err := StatusOf(deprecation.Default(), u, deprecation.ComponentVersions{deprecation.KubernetesComponent: "v1.22.0"}, StatusDefaults{LookAheadReleases: DefaultLookAheadReleases})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status.ApiVersionsStatus) != 3 || !status.ApiVersionsStatus[0].Removed || status.ApiVersionsStatus[1].Removed {
+		t.Fatalf("Unexpected API versions status: %+v", status.ApiVersionsStatus)
+	}
+	expected := apiversionv1beta1.FinalStatusResult{Deprecated: 1, Removed: 1, RemovedInNextRelease: 1, RemovedInNextTwoReleases: 1,
+		RemovedWithinLookAhead: 1, Severity: deprecation.RuleSeverityCritical, LookAheadReleases: DefaultLookAheadReleases}
+	if status.FinalStatus != expected {
+		t.Fatalf("Final status: %+v doesn't match the expected status: %+v", status.FinalStatus, expected)
+	}
+	if len(u.Status.ApiVersionsStatus) != 0 {
+		t.Fatalf("Expected the UsedApiVersions not to be modified, got: %+v", u.Status)
+	}
+}
+
+func TestStatusOfManagedKubernetes(t *testing.T) {
+	u := &apiversionv1beta1.UsedApiVersions{Spec: apiversionv1beta1.UsedApiVersionsSpec{UsedApiVersions: []apiversionv1beta1.APIVersionMeta{
+		{Kind: "Ingress", APIVersion: "extensions/v1beta1"},
+	}}}
+	for _, version := range []string{"v1.22.0-eks-8cb36c9", "v1.22.0-gke.1203001", "v1.22.0+k3s1", "v1.22.0+f67aeb3"} {
+		status, err := StatusOf(deprecation.Default(), u, deprecation.ComponentVersions{deprecation.KubernetesComponent: version}, StatusDefaults{LookAheadReleases: DefaultLookAheadReleases})
+		if err != nil {
+			t.Fatal(err)
+		}
+		apiStatus := status.ApiVersionsStatus[0]
+		if !apiStatus.Removed || apiStatus.ComponentVersion != "v1.22.0" {
+			t.Fatalf("Expected extensions/v1beta1 Ingress to be removed in %v, got: %+v", version, apiStatus)
+		}
+	}
+}
+
+func TestStatusOfInferredLifecycle(t *testing.T) {
+	u := &apiversionv1beta1.UsedApiVersions{Spec: apiversionv1beta1.UsedApiVersionsSpec{UsedApiVersions: []apiversionv1beta1.APIVersionMeta{
+		{Kind: "FlowSchema", APIVersion: "flowcontrol.apiserver.k8s.io/v1beta2"},
+		{Kind: "Ingress", APIVersion: "extensions/v1beta1"},
+		{Kind: "ResourceClaim", APIVersion: "resource.k8s.io/v1alpha2"},
+	}}}
+	dataset, err := deprecation.NewDataset(&deprecation.Versions{DeprecatedVersions: []*deprecation.Version{
+		{APIVersion: "flowcontrol.apiserver.k8s.io/v1beta2", Kind: "FlowSchema", IntroducedInVersion: "v1.23.0"},
+		{APIVersion: "extensions/v1beta1", Kind: "Ingress", DeprecatedInVersion: "v1.14.0", RemovedInVersion: "v1.22.0"},
+		{APIVersion: "resource.k8s.io/v1alpha2", Kind: "ResourceClaim", IntroducedInVersion: "v1.27.0"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions := deprecation.ComponentVersions{deprecation.KubernetesComponent: "v1.28.0"}
+	defaults := StatusDefaults{LookAheadReleases: DefaultLookAheadReleases}
+
+	status, err := StatusOf(dataset, u, versions, defaults)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.ApiVersionsStatus[0].Inferred || status.ApiVersionsStatus[0].Deprecated || status.FinalStatus.Inferred != 0 {
+		t.Fatalf("Expected the lifecycle not to be inferred unless enabled, got: %+v", status)
+	}
+
+	status, err = StatusOf(dataset.WithInferredLifecycle(), u, versions, defaults)
+	if err != nil {
+		t.Fatal(err)
+	}
+	flowSchema := status.ApiVersionsStatus[0]
+	if !flowSchema.Inferred || !flowSchema.Deprecated || flowSchema.DeprecatedInVersion != "v1.26.0" ||
+		flowSchema.RemovedInVersion != "v1.29.0" || !flowSchema.RemovedInNextRelease {
+		t.Fatalf("Unexpected inferred status: %+v", flowSchema)
+	}
+	if status.ApiVersionsStatus[1].Inferred {
+		t.Fatalf("Expected the curated rule not to be inferred, got: %+v", status.ApiVersionsStatus[1])
+	}
+	if alpha := status.ApiVersionsStatus[2]; !alpha.Inferred || !alpha.Unstable || alpha.Deprecated || alpha.Removed {
+		t.Fatalf("Expected the alpha API version to be inferred unstable, got: %+v", alpha)
+	}
+	if status.FinalStatus.Inferred != 2 || status.FinalStatus.Unstable != 1 {
+		t.Fatalf("Expected 2 inferred API versions and 1 unstable, got: %+v", status.FinalStatus)
+	}
+}
+
+func TestStatusOfFields(t *testing.T) {
+	u := &apiversionv1beta1.UsedApiVersions{Spec: apiversionv1beta1.UsedApiVersionsSpec{UsedApiVersions: []apiversionv1beta1.APIVersionMeta{
+		{Kind: "Ingress", APIVersion: "networking.k8s.io/v1beta1", Fields: []string{"spec.backend", "spec.rules"},
+			Annotations: []string{"kubernetes.io/ingress.class"}},
+		{Kind: "Deployment", APIVersion: "apps/v1", Annotations: []string{"seccomp.security.alpha.kubernetes.io/pod"}},
+	}}}
+	status, err := StatusOf(deprecation.Default(), u, deprecation.ComponentVersions{deprecation.KubernetesComponent: "v1.21.0"},
+		StatusDefaults{LookAheadReleases: DefaultLookAheadReleases})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []apiversionv1beta1.FieldStatus{
+		{Field: "spec.backend", Deprecated: true, DeprecatedInVersion: "v1.19.0", RemovedInVersion: "v1.22.0", Replacement: "spec.defaultBackend",
+			RemovedInNextRelease: true, RemovedInNextTwoReleases: true, ReleasesUntilRemoval: intPtr(1), RemovedWithinLookAhead: true},
+		{Field: "spec.rules", DeprecatedInVersion: "n/a", RemovedInVersion: "n/a", Replacement: "n/a"},
+		{Annotation: "kubernetes.io/ingress.class", Deprecated: true, DeprecatedInVersion: "v1.18.0", RemovedInVersion: "n/a",
+			Replacement: "spec.ingressClassName"},
+	}
+	if !reflect.DeepEqual(status.ApiVersionsStatus[0].FieldsStatus, expected) {
+		t.Fatalf("Fields status: %+v doesn't match the expected status: %+v", status.ApiVersionsStatus[0].FieldsStatus, expected)
+	}
+	if seccomp := status.ApiVersionsStatus[1].FieldsStatus; len(seccomp) != 1 || !seccomp[0].Deprecated || seccomp[0].Removed {
+		t.Fatalf("Unexpected status of the seccomp annotation: %+v", seccomp)
+	}
+	if status.FinalStatus.DeprecatedFields != 3 || status.FinalStatus.RemovedFields != 0 {
+		t.Fatalf("Unexpected final status: %+v", status.FinalStatus)
+	}
+}
+
+func TestStatusOfLookAhead(t *testing.T) {
+	u := &apiversionv1beta1.UsedApiVersions{Spec: apiversionv1beta1.UsedApiVersionsSpec{UsedApiVersions: []apiversionv1beta1.APIVersionMeta{
+		{Kind: "Ingress", APIVersion: "extensions/v1beta1"},
+		{Kind: "CronJob", APIVersion: "batch/v1beta1"},
+		{Kind: "HorizontalPodAutoscaler", APIVersion: "autoscaling/v2beta1"},
+		{Kind: "Deployment", APIVersion: "apps/v1"},
+	}}}
+	versions := deprecation.ComponentVersions{deprecation.KubernetesComponent: "v1.21.0"}
+	tests := []struct {
+		name      string
+		lookAhead *int
+		expected  int
+	}{
+		{name: "default", expected: 1},
+		{name: "four releases", lookAhead: intPtr(4), expected: 3},
+		{name: "removed only", lookAhead: intPtr(0), expected: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u.Spec.LookAheadReleases = tt.lookAhead
+			status, err := StatusOf(deprecation.Default(), u, versions, StatusDefaults{LookAheadReleases: DefaultLookAheadReleases})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status.FinalStatus.RemovedWithinLookAhead != tt.expected {
+				t.Fatalf("Expected %d API versions removed within the look-ahead, got: %+v", tt.expected, status.FinalStatus)
+			}
+			if releases := status.ApiVersionsStatus[1].ReleasesUntilRemoval; releases == nil || *releases != 4 {
+				t.Fatalf("Expected CronJob to be removed in 4 releases, got: %v", releases)
+			}
+			if releases := status.ApiVersionsStatus[3].ReleasesUntilRemoval; releases != nil {
+				t.Fatalf("Expected no releases until the removal of an API version without removal, got: %v", *releases)
+			}
+		})
+	}
+}
+
+func TestMissingReleases(t *testing.T) {
+	calendar := &deprecation.Calendar{Releases: []*deprecation.Release{{Version: "v1.36"}, {Version: "v1.37"}}}
+	statuses := []apiversionv1beta1.APIVersionStatus{
+		{Component: deprecation.KubernetesComponent, RemovedInVersion: "v1.38.0"},
+		{Component: deprecation.KubernetesComponent, RemovedInVersion: "v1.38.0"},
+		{Component: deprecation.KubernetesComponent, RemovedInVersion: "v1.37.0"},
+		{Component: deprecation.KubernetesComponent, RemovedInVersion: "v1.22.0", Removed: true},
+		{Component: deprecation.KubernetesComponent, RemovedInVersion: deprecation.NotAvailable},
+		{Component: "cert-manager", RemovedInVersion: "v1.6.0"},
+	}
+	versions := deprecation.ComponentVersions{deprecation.KubernetesComponent: "v1.39.2-eks-8cb36c9"}
+	if missing := missingReleases(calendar, versions, statuses); !reflect.DeepEqual(missing, []string{"v1.39", "v1.38"}) {
+		t.Fatalf("Unexpected releases missing from the calendar: %v", missing)
+	}
+	if missing := missingReleases(nil, versions, statuses); missing != nil {
+		t.Fatalf("Expected nothing missing without a calendar, got: %v", missing)
+	}
+	if missing := missingReleases(deprecation.DefaultCalendar(), deprecation.ComponentVersions{deprecation.KubernetesComponent: "v1.37.0"}, nil); missing != nil {
+		t.Fatalf("Expected the embedded calendar to list the current releases, got: %v", missing)
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestStatusOfTargetVersions(t *testing.T) {
+	u := &apiversionv1beta1.UsedApiVersions{Spec: apiversionv1beta1.UsedApiVersionsSpec{UsedApiVersions: []apiversionv1beta1.APIVersionMeta{
+		{Kind: "Ingress", APIVersion: "extensions/v1beta1"},
+		{Kind: "CronJob", APIVersion: "batch/v1beta1"},
+		{Kind: "Certificate", APIVersion: "cert-manager.io/v1alpha2"},
+		{Kind: "Deployment", APIVersion: "apps/v1"},
+		{Kind: "HorizontalPodAutoscaler", APIVersion: "autoscaling/v2"},
+	}}}
+	versions := deprecation.ComponentVersions{deprecation.KubernetesComponent: "v1.21.0"}
+	defaults := StatusDefaults{LookAheadReleases: DefaultLookAheadReleases, TargetVersions: []string{"v1.22.0", "v1.25.0"}}
+
+	status, err := StatusOf(deprecation.Default(), u, versions, defaults)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []apiversionv1beta1.TargetVersionStatus{
+		{Version: "v1.22.0", Deprecated: 2, Removed: 1, NotYetAvailable: 1, ApiVersions: []apiversionv1beta1.TargetAPIVersionStatus{
+			{APIVersion: "extensions/v1beta1", Kind: "Ingress", Deprecated: true, Removed: true, ReplacementAvailable: boolPtr(true)},
+			{APIVersion: "batch/v1beta1", Kind: "CronJob", Deprecated: true, ReplacementAvailable: boolPtr(true)},
+			{APIVersion: "apps/v1", Kind: "Deployment"},
+			{APIVersion: "autoscaling/v2", Kind: "HorizontalPodAutoscaler", NotYetAvailable: true},
+		}},
+		{Version: "v1.25.0", Deprecated: 2, Removed: 2, ApiVersions: []apiversionv1beta1.TargetAPIVersionStatus{
+			{APIVersion: "extensions/v1beta1", Kind: "Ingress", Deprecated: true, Removed: true, ReplacementAvailable: boolPtr(true)},
+			{APIVersion: "batch/v1beta1", Kind: "CronJob", Deprecated: true, Removed: true, ReplacementAvailable: boolPtr(true)},
+			{APIVersion: "apps/v1", Kind: "Deployment"},
+			{APIVersion: "autoscaling/v2", Kind: "HorizontalPodAutoscaler"},
+		}},
+	}
+	if !reflect.DeepEqual(status.TargetVersionsStatus, expected) {
+		t.Fatalf("Target versions status: %+v doesn't match the expected status: %+v", status.TargetVersionsStatus, expected)
+	}
+	if status.ApiVersionsStatus[0].Removed {
+		t.Fatalf("Expected the status of the installed Kubernetes version not to change, got: %+v", status.ApiVersionsStatus[0])
+	}
+	if status.FinalStatus.NotYetAvailable != 1 || !status.ApiVersionsStatus[4].NotYetAvailable {
+		t.Fatalf("Expected autoscaling/v2 not to be available yet in the installed Kubernetes version, got: %+v", status.FinalStatus)
+	}
+
+	// the targets of the UsedApiVersions take precedence over the defaults
+	u.Spec.TargetVersions = []string{"not-a-version"}
+	status, err = StatusOf(deprecation.Default(), u, versions, defaults)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status.TargetVersionsStatus) != 1 || status.TargetVersionsStatus[0].Error == "" {
+		t.Fatalf("Expected an error for an invalid target version, got: %+v", status.TargetVersionsStatus)
+	}
+}
+
+func TestStatusOfRemovalDate(t *testing.T) {
+	u := &apiversionv1beta1.UsedApiVersions{Spec: apiversionv1beta1.UsedApiVersionsSpec{UsedApiVersions: []apiversionv1beta1.APIVersionMeta{
+		{Kind: "CronJob", APIVersion: "batch/v1beta1"},
+		{Kind: "Certificate", APIVersion: "cert-manager.io/v1alpha2"},
+		{Kind: "Deployment", APIVersion: "apps/v1"},
+	}}}
+	versions := deprecation.ComponentVersions{deprecation.KubernetesComponent: "v1.23.0", "cert-manager": "v1.5.0"}
+	planned := &deprecation.Calendar{Releases: []*deprecation.Release{{Version: "v1.25", PlannedUpgradeDate: "2023-01-16"}}}
+	defaults := StatusDefaults{
+		LookAheadReleases: DefaultLookAheadReleases,
+		Calendar:          deprecation.MergeCalendars(deprecation.DefaultCalendar(), planned),
+		Now:               time.Date(2023, 1, 1, 9, 0, 0, 0, time.UTC),
+	}
+
+	status, err := StatusOf(deprecation.Default(), u, versions, defaults)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := status.ApiVersionsStatus[0]; s.RemovalDate != "2023-01-16" || !reflect.DeepEqual(s.DaysUntilRemoval, intPtr(15)) {
+		t.Fatalf("Expected batch/v1beta1 to be removed by the planned upgrade in 15 days, got: %+v", s)
+	}
+	for _, s := range status.ApiVersionsStatus[1:] {
+		if s.RemovalDate != "" || s.DaysUntilRemoval != nil {
+			t.Fatalf("Expected no removal date for %s %s, got: %+v", s.APIVersion, s.Kind, s)
+		}
+	}
+
+	defaults.Calendar = nil
+	if status, _ = StatusOf(deprecation.Default(), u, versions, defaults); status.ApiVersionsStatus[0].RemovalDate != "" {
+		t.Fatalf("Expected no removal date without a calendar, got: %+v", status.ApiVersionsStatus[0])
+	}
+}
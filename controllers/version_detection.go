@@ -0,0 +1,314 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/wayfair-incubator/k8s-used-api-versions/pkg/deprecation"
+)
+
+// DefaultVersionCacheTTL is how long a detected component version is used before it's detected again
+const DefaultVersionCacheTTL = 5 * time.Minute
+
+// VersionDetector detects the installed version of a component.
+type VersionDetector interface {
+	DetectVersion(ctx context.Context) (string, error)
+}
+
+// StaticVersion is a component version which is configured instead of detected.
+type StaticVersion string
+
+// DetectVersion returns the configured version.
+func (v StaticVersion) DetectVersion(_ context.Context) (string, error) {
+	return string(v), nil
+}
+
+// KubernetesVersionDetector detects the Kubernetes version from the API server.
+type KubernetesVersionDetector struct {
+	Discovery discovery.ServerVersionInterface
+}
+
+// DetectVersion returns the version of the API server.
+func (d *KubernetesVersionDetector) DetectVersion(_ context.Context) (string, error) {
+	info, err := d.Discovery.ServerVersion()
+	if err != nil {
+		return "", err
+	}
+	return info.String(), nil
+}
+
+// DeploymentImageDetector detects the version of a component from the image tag of its Deployment.
+type DeploymentImageDetector struct {
+	Reader     client.Reader
+	Deployment types.NamespacedName
+	// Container is the name of the container running the component, the first container when empty
+	Container string
+}
+
+//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get
+
+// DetectVersion returns the image tag of the container.
+func (d *DeploymentImageDetector) DetectVersion(ctx context.Context) (string, error) {
+	var deployment appsv1.Deployment
+	if err := d.Reader.Get(ctx, d.Deployment, &deployment); err != nil {
+		return "", err
+	}
+	for _, c := range deployment.Spec.Template.Spec.Containers {
+		if d.Container != "" && c.Name != d.Container {
+			continue
+		}
+		tag := imageTag(c.Image)
+		if tag == "" {
+			return "", fmt.Errorf("the image %s of the Deployment %s has no tag", c.Image, d.Deployment)
+		}
+		return tag, nil
+	}
+	return "", fmt.Errorf("the Deployment %s has no container %q", d.Deployment, d.Container)
+}
+
+// imageTag returns the tag of the image reference, the digest is ignored.
+func imageTag(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	i := strings.LastIndex(image, ":")
+	if i < 0 || i < strings.LastIndex(image, "/") {
+		return ""
+	}
+	return image[i+1:]
+}
+
+// crdGVK is the kind of the CustomResourceDefinitions read by the CRDLabelDetector.
+var crdGVK = schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}
+
+// CRDLabelDetector detects the version of a component from a label of one of its CustomResourceDefinitions.
+type CRDLabelDetector struct {
+	Reader client.Reader
+	// CRD is the name of the CustomResourceDefinition, such as certificates.cert-manager.io
+	CRD   string
+	Label string
+}
+
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get
+
+// DetectVersion returns the value of the label.
+func (d *CRDLabelDetector) DetectVersion(ctx context.Context) (string, error) {
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(crdGVK)
+	if err := d.Reader.Get(ctx, types.NamespacedName{Name: d.CRD}, crd); err != nil {
+		return "", err
+	}
+	version, ok := crd.GetLabels()[d.Label]
+	if !ok || version == "" {
+		return "", fmt.Errorf("the CustomResourceDefinition %s has no label %s", d.CRD, d.Label)
+	}
+	return version, nil
+}
+
+// ConfigMapKeyDetector detects the version of a component from a ConfigMap key.
+type ConfigMapKeyDetector struct {
+	Reader    client.Reader
+	ConfigMap types.NamespacedName
+	Key       string
+}
+
+// DetectVersion returns the trimmed value of the key.
+func (d *ConfigMapKeyDetector) DetectVersion(ctx context.Context) (string, error) {
+	var cm corev1.ConfigMap
+	if err := d.Reader.Get(ctx, d.ConfigMap, &cm); err != nil {
+		return "", err
+	}
+	version := strings.TrimSpace(cm.Data[d.Key])
+	if version == "" {
+		return "", fmt.Errorf("the ConfigMap %s has no key %s", d.ConfigMap, d.Key)
+	}
+	return version, nil
+}
+
+// ComponentVersionDetector detects the installed versions of the components and caches them.
+// When the detection of a component fails, its last detected version is used.
+type ComponentVersionDetector struct {
+	// Detectors are the version detectors by component name
+	Detectors map[string]VersionDetector
+	// TTL is how long a detected version is used before it's detected again
+	TTL time.Duration
+	Log logr.Logger
+
+	mu    sync.Mutex
+	cache map[string]cachedVersion
+	// now returns the current time, it's replaced by the tests
+	now func() time.Time
+}
+
+// cachedVersion is a detected component version.
+type cachedVersion struct {
+	version    string
+	detectedAt time.Time
+}
+
+// Versions returns the installed versions of the components, a component whose version can't be
+// detected is not included.
+func (d *ComponentVersionDetector) Versions(ctx context.Context) deprecation.ComponentVersions {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.cache == nil {
+		d.cache = make(map[string]cachedVersion, len(d.Detectors))
+	}
+	now := time.Now
+	if d.now != nil {
+		now = d.now
+	}
+
+	versions := make(deprecation.ComponentVersions, len(d.Detectors))
+	for component, detector := range d.Detectors {
+		cached, ok := d.cache[component]
+		if !ok || now().Sub(cached.detectedAt) >= d.TTL {
+			version, err := detector.DetectVersion(ctx)
+			if err != nil {
+				d.Log.Error(err, "unable to detect the installed version of the component", "component", component, "lastVersion", cached.version)
+			} else {
+				if version != cached.version {
+					d.Log.Info("Detected the installed version of the component.", "component", component, "version", version)
+				}
+				cached = cachedVersion{version: version, detectedAt: now()}
+				d.cache[component] = cached
+			}
+		}
+		if cached.version != "" {
+			versions[component] = cached.version
+		}
+	}
+	return versions
+}
+
+// VersionDetectionConfig configures where the installed versions of the components are read from.
+type VersionDetectionConfig struct {
+	Components []ComponentDetection `json:"components"`
+}
+
+// ComponentDetection configures where the installed version of a component is read from,
+// exactly one of the sources must be set.
+type ComponentDetection struct {
+	Name       string                 `json:"name"`
+	Deployment *DeploymentImageSource `json:"deployment,omitempty"`
+	CRDLabel   *CRDLabelSource        `json:"crdLabel,omitempty"`
+	ConfigMap  *ConfigMapKeySource    `json:"configMap,omitempty"`
+}
+
+// DeploymentImageSource reads the version from the image tag of a Deployment container.
+type DeploymentImageSource struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Container string `json:"container,omitempty"`
+}
+
+// CRDLabelSource reads the version from a label of a CustomResourceDefinition.
+type CRDLabelSource struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+}
+
+// ConfigMapKeySource reads the version from a ConfigMap key.
+type ConfigMapKeySource struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Key       string `json:"key"`
+}
+
+// LoadVersionDetectors reads the version detection config file and returns its detectors by component name.
+func LoadVersionDetectors(configFile string, reader client.Reader) (map[string]VersionDetector, error) {
+	content, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+	return ParseVersionDetectors(content, reader)
+}
+
+// ParseVersionDetectors parses the content of a version detection config file and returns its detectors
+// by component name.
+func ParseVersionDetectors(content []byte, reader client.Reader) (map[string]VersionDetector, error) {
+	var config VersionDetectionConfig
+	if err := yaml.UnmarshalStrict(content, &config); err != nil {
+		return nil, err
+	}
+	detectors := make(map[string]VersionDetector, len(config.Components))
+	for _, c := range config.Components {
+		if c.Name == "" {
+			return nil, fmt.Errorf("a component has no name")
+		}
+		if _, ok := detectors[c.Name]; ok {
+			return nil, fmt.Errorf("the component %s is configured more than once", c.Name)
+		}
+		detector, err := c.detector(reader)
+		if err != nil {
+			return nil, fmt.Errorf("invalid component %s: %w", c.Name, err)
+		}
+		detectors[c.Name] = detector
+	}
+	return detectors, nil
+}
+
+// detector returns the version detector of the configured source.
+func (c *ComponentDetection) detector(reader client.Reader) (VersionDetector, error) {
+	var detectors []VersionDetector
+	if s := c.Deployment; s != nil {
+		if s.Namespace == "" || s.Name == "" {
+			return nil, fmt.Errorf("the deployment must have a namespace and a name")
+		}
+		detectors = append(detectors, &DeploymentImageDetector{
+			Reader:     reader,
+			Deployment: types.NamespacedName{Namespace: s.Namespace, Name: s.Name},
+			Container:  s.Container,
+		})
+	}
+	if s := c.CRDLabel; s != nil {
+		if s.Name == "" || s.Label == "" {
+			return nil, fmt.Errorf("the crdLabel must have a name and a label")
+		}
+		detectors = append(detectors, &CRDLabelDetector{Reader: reader, CRD: s.Name, Label: s.Label})
+	}
+	if s := c.ConfigMap; s != nil {
+		if s.Namespace == "" || s.Name == "" || s.Key == "" {
+			return nil, fmt.Errorf("the configMap must have a namespace, a name and a key")
+		}
+		detectors = append(detectors, &ConfigMapKeyDetector{
+			Reader:    reader,
+			ConfigMap: types.NamespacedName{Namespace: s.Namespace, Name: s.Name},
+			Key:       s.Key,
+		})
+	}
+	if len(detectors) != 1 {
+		return nil, fmt.Errorf("exactly one of deployment, crdLabel and configMap must be set")
+	}
+	return detectors[0], nil
+}
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestImageTag(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{image: "quay.io/jetstack/cert-manager-controller:v1.8.0", want: "v1.8.0"},
+		{image: "registry.local:5000/istio/pilot:1.13.2", want: "1.13.2"},
+		{image: "istio/pilot:1.13.2@sha256:0123456789abcdef", want: "1.13.2"},
+		{image: "registry.local:5000/istio/pilot", want: ""},
+		{image: "istio/pilot@sha256:0123456789abcdef", want: ""},
+	}
+	for _, tt := range tests {
+		if got := imageTag(tt.image); got != tt.want {
+			t.Fatalf("imageTag(%q) = %q, expected %q", tt.image, got, tt.want)
+		}
+	}
+}
+
+func TestParseVersionDetectors(t *testing.T) {
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(crdGVK)
+	crd.SetName("applications.argoproj.io")
+	crd.SetLabels(map[string]string{"app.kubernetes.io/version": "v2.3.3"})
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "cert-manager", Name: "cert-manager"},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "sidecar", Image: "sidecar:v0.1.0"},
+				{Name: "cert-manager-controller", Image: "quay.io/jetstack/cert-manager-controller:v1.8.0"},
+			}}}},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "istio-system", Name: "istio-version"},
+			Data:       map[string]string{"version": "1.13.2\n"},
+		},
+		crd,
+	).Build()
+
+	detectors, err := ParseVersionDetectors([]byte(`components:
+- name: cert-manager
+  deployment:
+    namespace: cert-manager
+    name: cert-manager
+    container: cert-manager-controller
+- name: istio
+  configMap:
+    namespace: istio-system
+    name: istio-version
+    key: version
+- name: argocd
+  crdLabel:
+    name: applications.argoproj.io
+    label: app.kubernetes.io/version
+`), reader)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := map[string]string{"cert-manager": "v1.8.0", "istio": "1.13.2", "argocd": "v2.3.3"}
+	for component, version := range want {
+		got, err := detectors[component].DetectVersion(context.Background())
+		if err != nil || got != version {
+			t.Fatalf("Expected %s version %s, got: %q, %v", component, version, got, err)
+		}
+	}
+}
+
+func TestParseVersionDetectorsErrors(t *testing.T) {
+	tests := map[string]string{
+		"no name":        "components: [{configMap: {namespace: a, name: b, key: c}}]",
+		"no source":      "components: [{name: istio}]",
+		"two sources":    "components: [{name: istio, configMap: {namespace: a, name: b, key: c}, crdLabel: {name: a, label: b}}]",
+		"missing field":  "components: [{name: istio, deployment: {name: b}}]",
+		"unknown field":  "components: [{name: istio, helm: {release: istio}}]",
+		"duplicate name": "components: [{name: a, crdLabel: {name: a, label: b}}, {name: a, crdLabel: {name: a, label: b}}]",
+	}
+	for name, config := range tests {
+		if _, err := ParseVersionDetectors([]byte(config), nil); err == nil {
+			t.Fatalf("Expected an error for %s", name)
+		}
+	}
+}
+
+// countingDetector returns its version, or an error once failing is set, and counts the detections.
+type countingDetector struct {
+	version    string
+	failing    bool
+	detections int
+}
+
+func (d *countingDetector) DetectVersion(_ context.Context) (string, error) {
+	d.detections++
+	if d.failing {
+		return "", errors.New("detection failed")
+	}
+	return d.version, nil
+}
+
+func TestComponentVersionDetectorCache(t *testing.T) {
+	now := time.Now()
+	istio := &countingDetector{version: "1.13.2"}
+	d := &ComponentVersionDetector{
+		Detectors: map[string]VersionDetector{"istio": istio, "unknown": &countingDetector{failing: true}},
+		TTL:       time.Minute,
+		Log:       ctrl.Log,
+		now:       func() time.Time { return now },
+	}
+
+	versions := d.Versions(context.Background())
+	if versions["istio"] != "1.13.2" || len(versions) != 1 {
+		t.Fatalf("Unexpected versions: %v", versions)
+	}
+
+	istio.version = "1.14.1"
+	d.Versions(context.Background())
+	if istio.detections != 1 {
+		t.Fatalf("Expected the cached version to be used, got %d detections", istio.detections)
+	}
+
+	now = now.Add(time.Minute)
+	if versions := d.Versions(context.Background()); versions["istio"] != "1.14.1" {
+		t.Fatalf("Expected the version to be detected again after the TTL, got: %v", versions)
+	}
+
+	istio.failing = true
+	now = now.Add(time.Minute)
+	if versions := d.Versions(context.Background()); versions["istio"] != "1.14.1" {
+		t.Fatalf("Expected the last detected version to be used when the detection fails, got: %v", versions)
+	}
+}
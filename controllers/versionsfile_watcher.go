@@ -0,0 +1,142 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/wayfair-incubator/k8s-used-api-versions/pkg/deprecation"
+)
+
+// reloadDelay is how long the watcher waits for the file events to settle before reloading,
+// so a file which is still being written is not loaded.
+const reloadDelay = time.Second
+
+// VersionsFileWatcher watches a versions file and updates its source in the store when the file changes.
+// The file's directory is watched instead of the file itself, so a mounted ConfigMap, which is updated
+// by replacing a symlink, is reloaded as well.
+type VersionsFileWatcher struct {
+	// Path is the versions file path
+	Path string
+	// Source is the name of the dataset source loaded from the versions file
+	Source string
+	// Store holds the dataset merged from the versions file and the other sources
+	Store *deprecation.Store
+	Log   logr.Logger
+	// Reloaded receives an event every time the versions file is reloaded, it's used to requeue all the UsedApiVersions.
+	Reloaded chan<- event.GenericEvent
+
+	content []byte
+}
+
+// NewVersionsFileWatcher loads the versions file into the source of the store and creates a watcher for it.
+func NewVersionsFileWatcher(path, source string, store *deprecation.Store, reloaded chan<- event.GenericEvent, log logr.Logger) (*VersionsFileWatcher, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := deprecation.ParseVersions(content)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.Update(source, versions); err != nil {
+		return nil, err
+	}
+	logDatasetUpdated(log, store)
+	return &VersionsFileWatcher{
+		Path:     path,
+		Source:   source,
+		Store:    store,
+		Log:      log,
+		Reloaded: reloaded,
+		content:  content,
+	}, nil
+}
+
+// Start watches the versions file until the context is done.
+func (w *VersionsFileWatcher) Start(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(filepath.Dir(w.Path)); err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-watcher.Events:
+			timer.Reset(reloadDelay)
+		case err := <-watcher.Errors:
+			w.Log.Error(err, "error watching the versions file", "file", w.Path)
+		case <-timer.C:
+			w.reload()
+		}
+	}
+}
+
+// NeedLeaderElection returns false, so every replica keeps its dataset up to date.
+func (w *VersionsFileWatcher) NeedLeaderElection() bool {
+	return false
+}
+
+// reload loads the versions file and updates its source in the store, the current dataset is kept
+// when the file can't be read or parsed.
+func (w *VersionsFileWatcher) reload() {
+	content, err := ioutil.ReadFile(w.Path)
+	if err != nil {
+		w.Log.Error(err, "unable to read the versions file, keeping the current dataset", "file", w.Path)
+		return
+	}
+	if bytes.Equal(content, w.content) {
+		return
+	}
+	versions, err := deprecation.ParseVersions(content)
+	if err != nil {
+		w.Log.Error(err, "unable to parse the versions file, keeping the current dataset", "file", w.Path)
+		return
+	}
+	// Only the override files are allowed to be empty, an empty main versions file is most likely being written.
+	if versions.IsEmpty() && w.Source == VersionsFileSource {
+		w.Log.Info("The versions file has no deprecated API versions nor fields, keeping the current dataset.", "file", w.Path)
+		return
+	}
+	if err := w.Store.Update(w.Source, versions); err != nil {
+		w.Log.Error(err, "invalid versions file, keeping the current dataset", "file", w.Path)
+		return
+	}
+
+	w.content = content
+	w.Log.Info("Reloaded the versions file.", "file", w.Path, "apiVersions", len(versions.DeprecatedVersions),
+		"fields", len(versions.DeprecatedFields))
+	logDatasetUpdated(w.Log, w.Store)
+	notifyDatasetReloaded(w.Reloaded)
+}
@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const reloadedVersions = `deprecatedVersions:
+  - version: extensions/v1beta1
+    kind: Ingress
+    deprecatedInVersion: v1.14.0
+    removedInVersion: v1.22.0
+    replacementApi: networking.k8s.io/v1
+`
+
+const reloadedFields = `deprecatedFields:
+  - kind: Ingress
+    annotation: kubernetes.io/ingress.class
+    deprecatedInVersion: v1.18.0
+    replacement: spec.ingressClassName
+`
+
+func TestVersionsFileWatcherReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "versions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "versions.yaml")
+	original, err := ioutil.ReadFile(filepath.Join("..", "pkg", "deprecation", "versions.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, original, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := NewDatasetReloadedChannel()
+	w, err := NewVersionsFileWatcher(path, VersionsFileSource, NewDatasetStore(path, nil, ""), reloaded, ctrl.Log)
+	if err != nil {
+		t.Fatalf("Unexpected error loading the versions file: %v", err)
+	}
+	loaded := w.Store.Get()
+
+	files := []struct {
+		content  string
+		reloaded bool
+	}{
+		{"deprecatedVersions: [", false},
+		{"", false},
+		{"deprecatedVersions: []\n", false},
+		{reloadedFields, true},
+		{reloadedVersions, true},
+		{reloadedVersions, false},
+	}
+	for _, f := range files {
+		if err := ioutil.WriteFile(path, []byte(f.content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		w.reload()
+		reloaded := w.Store.Get() != loaded
+		if reloaded != f.reloaded {
+			t.Fatalf("Versions file: %q reloaded: %v. Expected: %v", f.content, reloaded, f.reloaded)
+		}
+		loaded = w.Store.Get()
+	}
+
+	if loaded.Len() != 1 {
+		t.Fatalf("Expected the reloaded dataset to have 1 API version, got: %d", loaded.Len())
+	}
+	if len(reloaded) != 1 {
+		t.Fatalf("Expected a single pending reload event, got: %d", len(reloaded))
+	}
+}
@@ -19,13 +19,18 @@ package main
 import (
 	"flag"
 	"os"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
@@ -33,6 +38,7 @@ import (
 
 	apiversionv1beta1 "github.com/wayfair-incubator/k8s-used-api-versions/api/v1beta1"
 	"github.com/wayfair-incubator/k8s-used-api-versions/controllers"
+	"github.com/wayfair-incubator/k8s-used-api-versions/pkg/deprecation"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -48,13 +54,56 @@ func init() {
 	//+kubebuilder:scaffold:scheme
 }
 
+// stringList is a flag which can be set more than once.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
 func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
 	var versionsFile string
+	var overrideVersionsFiles stringList
+	var versionsConfigMap string
+	var versionsConfigMapKey string
+	var componentVersions stringList
+	var versionDetectionConfig string
+	var versionCacheTTL time.Duration
+	var lookAheadReleases int
+	var targetVersions stringList
+	var calendarFile string
+	var inferLifecycle bool
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
-	flag.StringVar(&versionsFile, "versions-file", "config/versions.yaml", "The versions file (versions.yaml) used to check deprecations.")
+	flag.StringVar(&versionsFile, "versions-file", "", "The versions file (versions.yaml) used to check deprecations "+
+		"instead of the versions file compiled into the binary.")
+	flag.Var(&overrideVersionsFiles, "override-versions-file", "A versions file overriding the rules of the versions file. "+
+		"It can be set more than once, every file overrides the files before it.")
+	flag.StringVar(&versionsConfigMap, "versions-configmap", "", "The namespace/name of a ConfigMap holding a versions file "+
+		"overriding the rules of the versions files.")
+	flag.StringVar(&versionsConfigMapKey, "versions-configmap-key", controllers.DefaultConfigMapKey, "The key of the versions file in the ConfigMap.")
+	flag.Var(&componentVersions, "component-version", "The installed version of a component other than Kubernetes as name=version, "+
+		"such as cert-manager=v1.8.0. It can be set more than once and it takes precedence over the detected version.")
+	flag.StringVar(&versionDetectionConfig, "version-detection-config", "", "The config file listing where the installed versions "+
+		"of the components other than Kubernetes are detected from.")
+	flag.DurationVar(&versionCacheTTL, "version-cache-ttl", controllers.DefaultVersionCacheTTL, "How long a detected component version, "+
+		"or the kinds served by an API version, are used before they are detected again.")
+	flag.IntVar(&lookAheadReleases, "look-ahead-releases", controllers.DefaultLookAheadReleases, "The number of releases ahead "+
+		"the removals of the used API versions are looked for, unless a UsedApiVersions sets its own lookAheadReleases.")
+	flag.Var(&targetVersions, "target-version", "A target Kubernetes version such as v1.29.0 the used API versions are also "+
+		"checked against, unless a UsedApiVersions sets its own targetVersions. It can be set more than once.")
+	flag.StringVar(&calendarFile, "calendar-file", "", "A release calendar file adding dates to the upstream release calendar "+
+		"compiled into the binary, such as the planned upgrade dates.")
+	flag.BoolVar(&inferLifecycle, "infer-lifecycle", false, "Infer the deprecation and removal of the beta Kubernetes "+
+		"API versions which are not in the versions files from their introduced version and the Kubernetes deprecation policy, "+
+		"and flag the alpha ones unstable.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
@@ -80,15 +129,122 @@ func main() {
 		os.Exit(1)
 	}
 
+	datasetStore := controllers.NewDatasetStore(versionsFile, overrideVersionsFiles, versionsConfigMap)
+	datasetReloaded := controllers.NewDatasetReloadedChannel()
+	versionsFiles := map[string]string{}
+	if versionsFile != "" {
+		versionsFiles[versionsFile] = controllers.VersionsFileSource
+	} else if err := datasetStore.Update(controllers.DefaultSource, deprecation.Default().Versions()); err != nil {
+		setupLog.Error(err, "unable to load the default versions file")
+		os.Exit(1)
+	}
+	for _, path := range overrideVersionsFiles {
+		versionsFiles[path] = controllers.OverrideFileSource(path)
+	}
+	for path, source := range versionsFiles {
+		versionsFileWatcher, err := controllers.NewVersionsFileWatcher(path, source, datasetStore, datasetReloaded, ctrl.Log.WithName("versions-file"))
+		if err != nil {
+			setupLog.Error(err, "unable to load the versions file", "file", path)
+			os.Exit(1)
+		}
+		if err := mgr.Add(versionsFileWatcher); err != nil {
+			setupLog.Error(err, "unable to watch the versions file", "file", path)
+			os.Exit(1)
+		}
+	}
+	if versionsConfigMap != "" {
+		parts := strings.SplitN(versionsConfigMap, "/", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			setupLog.Error(nil, "the versions ConfigMap must be set as namespace/name", "configMap", versionsConfigMap)
+			os.Exit(1)
+		}
+		if err := mgr.Add(&controllers.ConfigMapWatcher{
+			Clientset: kubernetes.NewForConfigOrDie(mgr.GetConfig()),
+			ConfigMap: types.NamespacedName{Namespace: parts[0], Name: parts[1]},
+			Key:       versionsConfigMapKey,
+			Source:    controllers.ConfigMapSource(versionsConfigMap),
+			Store:     datasetStore,
+			Log:       ctrl.Log.WithName("versions-configmap"),
+			Reloaded:  datasetReloaded,
+		}); err != nil {
+			setupLog.Error(err, "unable to watch the versions ConfigMap", "configMap", versionsConfigMap)
+			os.Exit(1)
+		}
+	}
+	setupLog.Info("Loaded the deprecation sources.", "sources", datasetStore.Sources(),
+		"revision", datasetStore.Get().Revision(), "defaultRevision", deprecation.Default().Revision())
+
+	for _, target := range targetVersions {
+		if _, err := deprecation.NormalizeVersion(target); err != nil {
+			setupLog.Error(err, "invalid target version", "targetVersion", target)
+			os.Exit(1)
+		}
+	}
+
+	calendar := deprecation.DefaultCalendar()
+	if calendarFile != "" {
+		planned, err := deprecation.LoadCalendar(calendarFile)
+		if err != nil {
+			setupLog.Error(err, "unable to load the release calendar", "file", calendarFile)
+			os.Exit(1)
+		}
+		calendar = deprecation.MergeCalendars(calendar, planned)
+	}
+
+	versionDetectors := map[string]controllers.VersionDetector{}
+	if versionDetectionConfig != "" {
+		versionDetectors, err = controllers.LoadVersionDetectors(versionDetectionConfig, mgr.GetAPIReader())
+		if err != nil {
+			setupLog.Error(err, "unable to load the version detection config", "file", versionDetectionConfig)
+			os.Exit(1)
+		}
+	}
+	for _, cv := range componentVersions {
+		parts := strings.SplitN(cv, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			setupLog.Error(nil, "the component version must be set as name=version", "componentVersion", cv)
+			os.Exit(1)
+		}
+		versionDetectors[parts[0]] = controllers.StaticVersion(parts[1])
+	}
+	versionDetectors[deprecation.KubernetesComponent] = &controllers.KubernetesVersionDetector{
+		Discovery: discovery.NewDiscoveryClientForConfigOrDie(mgr.GetConfig()),
+	}
+
 	if err = (&controllers.UsedApiVersionsReconciler{
-		Client:       mgr.GetClient(),
-		Scheme:       mgr.GetScheme(),
-		ClientConfig: mgr.GetConfig(),
-		VersionsFile: versionsFile,
+		Client:          mgr.GetClient(),
+		Log:             ctrl.Log.WithName("controllers").WithName("UsedApiVersions"),
+		Scheme:          mgr.GetScheme(),
+		ClientConfig:    mgr.GetConfig(),
+		DatasetStore:    datasetStore,
+		DatasetReloaded: datasetReloaded,
+		VersionDetector: &controllers.ComponentVersionDetector{
+			Detectors: versionDetectors,
+			TTL:       versionCacheTTL,
+			Log:       ctrl.Log.WithName("version-detection"),
+		},
+		LookAheadReleases: lookAheadReleases,
+		TargetVersions:    targetVersions,
+		Calendar:          calendar,
+		InferLifecycle:    inferLifecycle,
+		ServedAPIs: &controllers.ServedAPIs{
+			Discovery: discovery.NewDiscoveryClientForConfigOrDie(mgr.GetConfig()),
+			TTL:       versionCacheTTL,
+		},
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "UsedApiVersions")
 		os.Exit(1)
 	}
+	if err = (&controllers.DeprecationRuleReconciler{
+		Client:          mgr.GetClient(),
+		Log:             ctrl.Log.WithName("controllers").WithName("DeprecationRule"),
+		Scheme:          mgr.GetScheme(),
+		DatasetStore:    datasetStore,
+		DatasetReloaded: datasetReloaded,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "DeprecationRule")
+		os.Exit(1)
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
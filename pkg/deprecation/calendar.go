@@ -0,0 +1,202 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deprecation
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math"
+	"time"
+
+	semver "github.com/hashicorp/go-version"
+	"sigs.k8s.io/yaml"
+)
+
+// DateFormat is the format of the dates of the release calendar, such as 2022-08-23.
+const DateFormat = "2006-01-02"
+
+// Calendar lists the dates of the Kubernetes minor releases.
+type Calendar struct {
+	Releases []*Release `json:"releases" yaml:"releases"`
+}
+
+// Release holds the dates of a Kubernetes minor release, the dates which are not set are unknown.
+type Release struct {
+	// Version is the minor release such as v1.25, its patch is ignored
+	Version string `json:"version" yaml:"version"`
+	// ReleaseDate is the date the release is published upstream
+	ReleaseDate string `json:"releaseDate,omitempty" yaml:"releaseDate,omitempty"`
+	// EndOfLifeDate is the date the release stops receiving patches upstream
+	EndOfLifeDate string `json:"endOfLifeDate,omitempty" yaml:"endOfLifeDate,omitempty"`
+	// PlannedUpgradeDate is the date the clusters are planned to be upgraded to the release
+	PlannedUpgradeDate string `json:"plannedUpgradeDate,omitempty" yaml:"plannedUpgradeDate,omitempty"`
+}
+
+// minorRelease identifies a minor release.
+type minorRelease struct {
+	major, minor int
+}
+
+func minorOf(v *semver.Version) minorRelease {
+	segments := v.Segments()
+	return minorRelease{major: segments[0], minor: segments[1]}
+}
+
+// ParseCalendar parses the content of a release calendar file, and checks its versions and dates.
+func ParseCalendar(content []byte) (*Calendar, error) {
+	c := new(Calendar)
+	if err := yaml.UnmarshalStrict(content, c); err != nil {
+		return nil, err
+	}
+	seen := make(map[minorRelease]bool, len(c.Releases))
+	for _, r := range c.Releases {
+		v, err := semver.NewVersion(r.Version)
+		if err != nil {
+			return nil, fmt.Errorf("invalid release version %q: %w", r.Version, err)
+		}
+		if seen[minorOf(v)] {
+			return nil, fmt.Errorf("the release %s is listed more than once", r.Version)
+		}
+		seen[minorOf(v)] = true
+		for _, d := range []struct{ name, date string }{
+			{name: "releaseDate", date: r.ReleaseDate},
+			{name: "endOfLifeDate", date: r.EndOfLifeDate},
+			{name: "plannedUpgradeDate", date: r.PlannedUpgradeDate},
+		} {
+			if _, err := parseDate(d.date); err != nil {
+				return nil, fmt.Errorf("invalid %s of %s: %w", d.name, r.Version, err)
+			}
+		}
+	}
+	return c, nil
+}
+
+// LoadCalendar reads a release calendar file.
+func LoadCalendar(file string) (*Calendar, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCalendar(content)
+}
+
+// MergeCalendars returns the releases of the calendars, the dates set by a calendar override the ones of the
+// calendars before it. It's used to add the planned upgrade dates to the upstream calendar.
+func MergeCalendars(calendars ...*Calendar) *Calendar {
+	merged := new(Calendar)
+	index := make(map[minorRelease]*Release)
+	for _, c := range calendars {
+		if c == nil {
+			continue
+		}
+		for _, r := range c.Releases {
+			v, err := semver.NewVersion(r.Version)
+			if err != nil {
+				continue
+			}
+			existing, ok := index[minorOf(v)]
+			if !ok {
+				copied := *r
+				index[minorOf(v)] = &copied
+				merged.Releases = append(merged.Releases, &copied)
+				continue
+			}
+			if r.ReleaseDate != "" {
+				existing.ReleaseDate = r.ReleaseDate
+			}
+			if r.EndOfLifeDate != "" {
+				existing.EndOfLifeDate = r.EndOfLifeDate
+			}
+			if r.PlannedUpgradeDate != "" {
+				existing.PlannedUpgradeDate = r.PlannedUpgradeDate
+			}
+		}
+	}
+	return merged
+}
+
+// Lists checks if the calendar lists the minor release of the version.
+func (c *Calendar) Lists(version *semver.Version) bool {
+	if c == nil || version == nil {
+		return false
+	}
+	for _, r := range c.Releases {
+		if v, err := semver.NewVersion(r.Version); err == nil && minorOf(v) == minorOf(version) {
+			return true
+		}
+	}
+	return false
+}
+
+// RemovalDate returns the date a cluster is expected to run the release which removes an API version:
+//
+//   - the first planned upgrade to the removal release or a later one
+//   - otherwise the upstream date of the removal release
+//   - otherwise the end of life of the last release serving the API version, when the upgrade can't be delayed anymore,
+//     for a removal release which is not in the calendar yet
+//
+// It returns false when none of these dates is known.
+func (c *Calendar) RemovalDate(removedIn *semver.Version) (time.Time, bool) {
+	if c == nil || removedIn == nil {
+		return time.Time{}, false
+	}
+	removal := minorOf(removedIn)
+	var planned, endOfLife, released time.Time
+	for _, r := range c.Releases {
+		v, err := semver.NewVersion(r.Version)
+		if err != nil {
+			continue
+		}
+		release := minorOf(v)
+		if release.major != removal.major {
+			continue
+		}
+		if date, err := parseDate(r.PlannedUpgradeDate); err == nil && !date.IsZero() && release.minor >= removal.minor &&
+			(planned.IsZero() || date.Before(planned)) {
+			planned = date
+		}
+		if release.minor == removal.minor-1 {
+			endOfLife, _ = parseDate(r.EndOfLifeDate)
+		}
+		if release.minor == removal.minor {
+			released, _ = parseDate(r.ReleaseDate)
+		}
+	}
+	for _, date := range []time.Time{planned, released, endOfLife} {
+		if !date.IsZero() {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// DaysUntil returns the number of days from now until the date, rounded up and 0 once the date is reached.
+func DaysUntil(date, now time.Time) int {
+	days := int(math.Ceil(date.Sub(now).Hours() / 24))
+	if days < 0 {
+		return 0
+	}
+	return days
+}
+
+// parseDate parses a date of the release calendar, an empty date is the zero time.
+func parseDate(date string) (time.Time, error) {
+	if date == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(DateFormat, date)
+}
@@ -0,0 +1,123 @@
+package deprecation
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	semver "github.com/hashicorp/go-version"
+)
+
+func TestParseCalendar(t *testing.T) {
+	invalid := []string{
+		"releases:\n  - version: one\n",
+		"releases:\n  - version: v1.25\n    releaseDate: 23/08/2022\n",
+		"releases:\n  - version: v1.25\n  - version: v1.25.1\n",
+		"releases:\n  - version: v1.25\n    releasedOn: 2022-08-23\n",
+	}
+	for _, content := range invalid {
+		if _, err := ParseCalendar([]byte(content)); err == nil {
+			t.Fatalf("Expected an error parsing %q", content)
+		}
+	}
+	if c := DefaultCalendar(); len(c.Releases) == 0 {
+		t.Fatalf("Expected the embedded calendar to have releases")
+	}
+}
+
+func TestCalendarLists(t *testing.T) {
+	c := &Calendar{Releases: []*Release{{Version: "v1.24"}, {Version: "v1.25"}}}
+	tests := map[string]bool{"v1.24.0": true, "v1.25.12": true, "v1.26.0": false, "v2.25.0": false}
+	for version, expected := range tests {
+		if listed := c.Lists(semver.Must(semver.NewVersion(version))); listed != expected {
+			t.Fatalf("Expected %s to be listed: %t, got: %t", version, expected, listed)
+		}
+	}
+	var empty *Calendar
+	if empty.Lists(semver.Must(semver.NewVersion("v1.24.0"))) {
+		t.Fatalf("Expected no release to be listed without a calendar")
+	}
+}
+
+func TestCalendarRemovalDate(t *testing.T) {
+	upstream := &Calendar{Releases: []*Release{
+		{Version: "v1.24", ReleaseDate: "2022-05-03", EndOfLifeDate: "2023-07-28"},
+		{Version: "v1.25", ReleaseDate: "2022-08-23", EndOfLifeDate: "2023-10-27"},
+		{Version: "v1.26", ReleaseDate: "2022-12-09", EndOfLifeDate: "2024-02-28"},
+	}}
+	planned := &Calendar{Releases: []*Release{
+		{Version: "v1.25", PlannedUpgradeDate: "2023-03-01"},
+		{Version: "v1.27", PlannedUpgradeDate: "2023-09-01"},
+	}}
+	merged := MergeCalendars(upstream, planned)
+	if merged.Releases[1].ReleaseDate != "2022-08-23" || merged.Releases[1].PlannedUpgradeDate != "2023-03-01" {
+		t.Fatalf("Expected the planned upgrade date to be added to the upstream dates, got: %+v", merged.Releases[1])
+	}
+	if upstream.Releases[1].PlannedUpgradeDate != "" {
+		t.Fatalf("Expected the merged calendars not to be modified")
+	}
+
+	tests := []struct {
+		calendar  *Calendar
+		removedIn string
+		expected  string
+	}{
+		{calendar: upstream, removedIn: "v1.25.0", expected: "2022-08-23"},
+		{calendar: upstream, removedIn: "v1.24.0", expected: "2022-05-03"},
+		{calendar: upstream, removedIn: "v1.27.0", expected: "2024-02-28"},
+		{calendar: upstream, removedIn: "v1.28.0"},
+		{calendar: upstream, removedIn: "v2.0.0"},
+		{calendar: merged, removedIn: "v1.25.0", expected: "2023-03-01"},
+		{calendar: merged, removedIn: "v1.26.0", expected: "2023-09-01"},
+		{calendar: nil, removedIn: "v1.25.0"},
+	}
+	for _, tt := range tests {
+		date, ok := tt.calendar.RemovalDate(semver.Must(semver.NewVersion(tt.removedIn)))
+		got := ""
+		if ok {
+			got = date.Format(DateFormat)
+		}
+		if got != tt.expected {
+			t.Fatalf("Removed in %s: expected the removal date %q, got %q", tt.removedIn, tt.expected, got)
+		}
+	}
+}
+
+func TestScheduleRemoval(t *testing.T) {
+	calendar := &Calendar{Releases: []*Release{{Version: "v1.24", EndOfLifeDate: "2023-07-28"}}}
+	d, err := NewDataset(&Versions{DeprecatedVersions: []*Version{
+		{APIVersion: "policy/v1beta1", Kind: "PodSecurityPolicy", DeprecatedInVersion: "v1.21.0", RemovedInVersion: "v1.25.0"},
+		{APIVersion: "cert-manager.io/v1alpha2", Kind: "Certificate", RemovedInVersion: "v1.25.0", Component: "cert-manager"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2023, 7, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		kind       string
+		apiVersion string
+		versions   ComponentVersions
+		expected   string
+	}{
+		{kind: "PodSecurityPolicy", apiVersion: "policy/v1beta1", versions: ComponentVersions{"k8s": "v1.24.0"}, expected: "27"},
+		{kind: "PodSecurityPolicy", apiVersion: "policy/v1beta1", versions: ComponentVersions{"k8s": "v1.25.0"}, expected: "0"},
+		{kind: "Certificate", apiVersion: "cert-manager.io/v1alpha2", versions: ComponentVersions{"cert-manager": "v1.24.0"}, expected: "nil"},
+	}
+	for _, tt := range tests {
+		got, err := d.CheckComponent("", tt.kind, tt.apiVersion, tt.versions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got.ScheduleRemoval(calendar, now)
+		days := "nil"
+		if got.DaysUntilRemoval != nil {
+			days = fmt.Sprint(*got.DaysUntilRemoval)
+			if got.RemovalDate.Format(DateFormat) != "2023-07-28" {
+				t.Fatalf("%s %s: unexpected removal date %s", tt.apiVersion, tt.kind, got.RemovalDate)
+			}
+		}
+		if days != tt.expected {
+			t.Fatalf("%s %s: expected %s days until removal, got %s", tt.apiVersion, tt.kind, tt.expected, days)
+		}
+	}
+}
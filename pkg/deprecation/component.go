@@ -0,0 +1,65 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deprecation
+
+import (
+	"fmt"
+
+	semver "github.com/hashicorp/go-version"
+)
+
+// KubernetesComponent is the component of the Kubernetes API versions, it's the default component of a rule.
+const KubernetesComponent = "k8s"
+
+// UnknownComponentVersionError is returned when the installed version of a component is unknown, or isn't a valid
+// version such as the "latest" image tag, so the deprecation status of its API versions can't be checked.
+type UnknownComponentVersionError struct {
+	// Component is the name of the component
+	Component string
+	// Version is the installed version which isn't valid, empty when the version is unknown
+	Version string
+	// Err is the error parsing the installed version, nil when the version is unknown
+	Err error
+}
+
+func (e *UnknownComponentVersionError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("invalid installed version %q of the component %s: %v", e.Version, e.Component, e.Err)
+	}
+	return "unknown installed version of the component: " + e.Component
+}
+
+func (e *UnknownComponentVersionError) Unwrap() error {
+	return e.Err
+}
+
+// installedVersion returns the installed version of the component, an UnknownComponentVersionError when it's
+// unknown or can't be parsed.
+func installedVersion(component string, versions ComponentVersions) (*semver.Version, error) {
+	installed, ok := versions[component]
+	if !ok || installed == "" {
+		return nil, &UnknownComponentVersionError{Component: component}
+	}
+	current, err := NormalizeVersion(installed)
+	if err != nil {
+		return nil, &UnknownComponentVersionError{Component: component, Version: installed, Err: err}
+	}
+	return current, nil
+}
+
+// ComponentVersions maps a component name such as "k8s" or "cert-manager" to its installed version.
+type ComponentVersions map[string]string
@@ -0,0 +1,360 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deprecation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	semver "github.com/hashicorp/go-version"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Dataset is a parsed versions file indexed by component, group, version and kind.
+// A Dataset is read-only once created, so it's safe to share it between goroutines.
+type Dataset struct {
+	versions *Versions
+	index    map[ruleKey]*rule
+	// position is the position of every rule in the versions
+	position map[ruleKey]int
+	// components lists the components of every group and version in the order they are added
+	components map[schema.GroupVersion][]string
+	// patterns lists the rules with a kind pattern of every component, group and version in the order they are added
+	patterns map[groupKey][]ruleKey
+	// fields lists the rules of the deprecated fields and annotations in the order they are added
+	fields []*fieldRule
+	// fieldPosition is the position of every field rule in the fields
+	fieldPosition map[fieldKey]int
+	// revision identifies the content of the dataset
+	revision string
+	// infer enables the lifecycle inference of the pre-GA Kubernetes API versions, see WithInferredLifecycle
+	infer bool
+	// introduced is the Kubernetes rule introduced first of every group and version, only set when inferring
+	introduced map[schema.GroupVersion]*rule
+}
+
+// ruleKey identifies a rule of the dataset.
+type ruleKey struct {
+	component string
+	gvk       schema.GroupVersionKind
+}
+
+// groupKey identifies the rules of a component, group and version.
+type groupKey struct {
+	component string
+	gv        schema.GroupVersion
+}
+
+const (
+	// AnyKind is the kind of a rule matching every kind of its group and version
+	AnyKind = "*"
+	// listSuffix is the suffix of the list kinds, which match the rule of their item kind
+	listSuffix = "List"
+)
+
+// isKindPattern checks if the kind of a rule is a pattern such as "*" or "Flow*" rather than a kind.
+func isKindPattern(kind string) bool {
+	return strings.ContainsAny(kind, `*?[\`)
+}
+
+// keyOf returns the dataset key of the deprecated API version.
+func keyOf(dep *Version) ruleKey {
+	return ruleKey{component: componentOf(dep.Component), gvk: schema.FromAPIVersionAndKind(dep.APIVersion, dep.Kind)}
+}
+
+// componentOf returns the component name, an empty component is Kubernetes.
+func componentOf(component string) string {
+	if component == "" {
+		return KubernetesComponent
+	}
+	return component
+}
+
+// newDataset creates an empty Dataset.
+func newDataset() *Dataset {
+	return &Dataset{
+		versions:   new(Versions),
+		index:      make(map[ruleKey]*rule),
+		position:   make(map[ruleKey]int),
+		components: make(map[schema.GroupVersion][]string),
+		patterns:   make(map[groupKey][]ruleKey),
+
+		fieldPosition: make(map[fieldKey]int),
+	}
+}
+
+// set adds the rule to the dataset or replaces the rule with the same key.
+func (d *Dataset) set(key ruleKey, r *rule) {
+	if i, ok := d.position[key]; ok {
+		d.versions.DeprecatedVersions[i] = r.Version
+	} else {
+		d.position[key] = len(d.versions.DeprecatedVersions)
+		d.versions.DeprecatedVersions = append(d.versions.DeprecatedVersions, r.Version)
+		gv := key.gvk.GroupVersion()
+		if !containsString(d.components[gv], key.component) {
+			d.components[gv] = append(d.components[gv], key.component)
+		}
+		if kind := key.gvk.Kind; kind != AnyKind && isKindPattern(kind) {
+			group := groupKey{component: key.component, gv: gv}
+			d.patterns[group] = append(d.patterns[group], key)
+		}
+	}
+	d.index[key] = r
+}
+
+// rule is a deprecated API version with its versions already parsed.
+type rule struct {
+	*Version
+	// source is the name of the source the rule is loaded from
+	source string
+	// inferred is true when the versions are inferred from the deprecation policy
+	inferred bool
+	// unstable is true when the API version can be removed in any release, see Result.Unstable
+	unstable            bool
+	introducedInVersion *semver.Version
+	deprecatedInVersion *semver.Version
+	removedInVersion    *semver.Version
+}
+
+// newRule parses the versions of the deprecated API version.
+func newRule(dep *Version, source string) (*rule, error) {
+	r := &rule{Version: dep, source: source}
+	if _, err := path.Match(dep.Kind, ""); err != nil {
+		return nil, fmt.Errorf("invalid kind pattern of %s %s: %w", dep.APIVersion, dep.Kind, err)
+	}
+	var err error
+	if r.introducedInVersion, err = parseVersion(dep.IntroducedInVersion); err != nil {
+		return nil, fmt.Errorf("invalid introducedInVersion of %s %s: %w", dep.APIVersion, dep.Kind, err)
+	}
+	if r.deprecatedInVersion, err = parseVersion(dep.DeprecatedInVersion); err != nil {
+		return nil, fmt.Errorf("invalid deprecatedInVersion of %s %s: %w", dep.APIVersion, dep.Kind, err)
+	}
+	if r.removedInVersion, err = parseVersion(dep.RemovedInVersion); err != nil {
+		return nil, fmt.Errorf("invalid removedInVersion of %s %s: %w", dep.APIVersion, dep.Kind, err)
+	}
+	if !IsRuleSeverity(dep.Severity) {
+		return nil, fmt.Errorf("invalid severity of %s %s: %q is not one of info, warning and critical", dep.APIVersion, dep.Kind, dep.Severity)
+	}
+	return r, nil
+}
+
+// NewDataset parses and indexes the provided deprecated API versions.
+// When the same kind and API version is listed more than once, the first entry is used.
+func NewDataset(v *Versions) (*Dataset, error) {
+	d, _, err := Merge(Source{Versions: v})
+	return d, err
+}
+
+// LoadDataset reads the versions file and returns it as a Dataset.
+func LoadDataset(versionsFile string) (*Dataset, error) {
+	v, err := getDeprecatedVersions(versionsFile)
+	if err != nil {
+		return nil, err
+	}
+	return NewDataset(v)
+}
+
+// ParseDataset parses the content of a versions file and returns it as a Dataset.
+func ParseDataset(content []byte) (*Dataset, error) {
+	v, err := ParseVersions(content)
+	if err != nil {
+		return nil, err
+	}
+	return NewDataset(v)
+}
+
+// Versions returns the deprecated API versions the dataset was created from.
+func (d *Dataset) Versions() *Versions {
+	return d.versions
+}
+
+// Revision returns a short hash of the deprecated API versions, it changes every time a rule changes.
+func (d *Dataset) Revision() string {
+	return d.revision
+}
+
+// revisionOf returns a short hash of the deprecated API versions.
+func revisionOf(v *Versions) string {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:6])
+}
+
+// Len returns the number of indexed deprecated API versions, the deprecated fields and annotations are not counted.
+func (d *Dataset) Len() int {
+	return len(d.index)
+}
+
+// lookup returns the rule of the API version of specific kind served by the component, nil if it's not in the dataset.
+// An empty component matches the rule of any component, Kubernetes rules first.
+func (d *Dataset) lookup(component, kind, apiVersion string) *rule {
+	gvk := schema.FromAPIVersionAndKind(apiVersion, kind)
+	if component != "" {
+		return d.match(component, gvk)
+	}
+	if r := d.match(KubernetesComponent, gvk); r != nil {
+		return r
+	}
+	for _, c := range d.components[gvk.GroupVersion()] {
+		if r := d.match(c, gvk); r != nil {
+			return r
+		}
+	}
+	return nil
+}
+
+// match returns the rule of the component matching the group, version and kind, the most specific one first:
+//
+//  1. the rule of the kind
+//  2. the rule of the item kind of a list kind, such as RoleList matching the rule of Role
+//  3. the first rule whose kind pattern matches the kind or its item kind, such as "Flow*"
+//  4. the rule of the whole group and version, whose kind is "*"
+func (d *Dataset) match(component string, gvk schema.GroupVersionKind) *rule {
+	if r, ok := d.index[ruleKey{component: component, gvk: gvk}]; ok {
+		return r
+	}
+	item := strings.TrimSuffix(gvk.Kind, listSuffix)
+	isList := item != gvk.Kind && item != ""
+	if isList {
+		if r, ok := d.index[ruleKey{component: component, gvk: gvk.GroupVersion().WithKind(item)}]; ok {
+			return r
+		}
+	}
+	for _, key := range d.patterns[groupKey{component: component, gv: gvk.GroupVersion()}] {
+		if matchKind(key.gvk.Kind, gvk.Kind) || (isList && matchKind(key.gvk.Kind, item)) {
+			return d.index[key]
+		}
+	}
+	return d.index[ruleKey{component: component, gvk: gvk.GroupVersion().WithKind(AnyKind)}]
+}
+
+// matchKind checks if the kind matches the pattern, the pattern is validated when the rule is created.
+func matchKind(pattern, kind string) bool {
+	ok, _ := path.Match(pattern, kind)
+	return ok
+}
+
+// containsString checks if the value is in the list.
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Check checks the deprecation status of the Kubernetes API version of specific kind against the k8s version.
+// An API version which is not in the dataset is returned with Known set to false.
+func (d *Dataset) Check(kind, apiVersion, k8sVersion string) (*Result, error) {
+	return d.CheckComponent(KubernetesComponent, kind, apiVersion, ComponentVersions{KubernetesComponent: k8sVersion})
+}
+
+// CheckComponent checks the deprecation status of the API version of specific kind served by the component
+// against the installed version of the component. An empty component matches the rule of any component.
+// An API version which is not in the dataset is returned with Known set to false.
+// When the installed version of the matching component is not in the versions or isn't a valid version, the result
+// is returned without its status together with an UnknownComponentVersionError.
+func (d *Dataset) CheckComponent(component, kind, apiVersion string, versions ComponentVersions) (*Result, error) {
+	result := &Result{Kind: kind, APIVersion: apiVersion, Component: component}
+	r := d.lookup(component, kind, apiVersion)
+	if d.infer {
+		if inferred := d.inferRule(r, component, kind, apiVersion); inferred != nil {
+			r = inferred
+		}
+	}
+	if r == nil {
+		return result, nil
+	}
+
+	result.Known = true
+	result.Inferred = r.inferred
+	result.Unstable = r.unstable
+	result.Component = componentOf(r.Component)
+	result.Source = r.source
+	result.IntroducedInVersion = r.introducedInVersion
+	result.DeprecatedInVersion = r.deprecatedInVersion
+	result.RemovedInVersion = r.removedInVersion
+	if r.ReplacementAPI != "" {
+		result.Replacement = &Replacement{APIVersion: r.ReplacementAPI, Kind: kind}
+	}
+	result.Severity = r.Severity
+	result.MigrationNote = r.MigrationNote
+	result.MigrationGuide = r.MigrationGuide
+	result.FieldChanges = r.FieldChanges
+
+	current, err := installedVersion(result.Component, versions)
+	if err != nil {
+		return result, err
+	}
+	result.ComponentVersion = current
+
+	result.NotYetAvailable = r.introducedInVersion != nil && !isReached(current, r.introducedInVersion)
+	result.Deprecated = isReached(current, r.deprecatedInVersion)
+	result.Removed = isReached(current, r.removedInVersion)
+	result.RemovedInNextRelease = isReached(nextMinor(current, 1), r.removedInVersion)
+	result.RemovedInNextTwoReleases = isReached(nextMinor(current, 2), r.removedInVersion)
+	result.ReleasesUntilRemoval = releasesUntil(current, r.removedInVersion)
+
+	return result, nil
+}
+
+// isReached checks if the component version is equal or greater than the version from the dataset,
+// a version which is not set is never reached.
+func isReached(current, version *semver.Version) bool {
+	if version == nil {
+		return false
+	}
+	return current.GreaterThanOrEqual(version)
+}
+
+// releasesUntil returns the number of minor releases from the component version until the version, 0 when it's
+// already reached. It's nil when the version is not set or its major is different, since the number of minor
+// releases of a major isn't known.
+func releasesUntil(current, version *semver.Version) *int {
+	if version == nil {
+		return nil
+	}
+	releases := 0
+	if !isReached(current, version) {
+		c, v := current.Segments(), version.Segments()
+		if c[0] != v[0] {
+			return nil
+		}
+		// a version such as v1.22.3 is reached by the next minor release of v1.22.0
+		releases = v[1] - c[1]
+		if releases < 1 {
+			releases = 1
+		}
+	}
+	return &releases
+}
+
+// nextMinor returns the version after incrementing its minor by specific value,
+// the pre-release and metadata are dropped.
+func nextMinor(version *semver.Version, steps int) *semver.Version {
+	segments := version.Segments()
+	v, _ := semver.NewVersion(fmt.Sprintf("%d.%d.%d", segments[0], segments[1]+steps, segments[2]))
+	return v
+}
@@ -0,0 +1,324 @@
+package deprecation
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestLoadDataset(t *testing.T) {
+	d, err := LoadDataset(versionsFile)
+	if err != nil {
+		t.Fatalf("Unexpected error loading the versions file: %v", err)
+	}
+	if d.Len() == 0 || d.Len() > len(d.Versions().DeprecatedVersions) {
+		t.Fatalf("Dataset has %d indexed API versions out of %d", d.Len(), len(d.Versions().DeprecatedVersions))
+	}
+
+	if _, err := LoadDataset("does-not-exist.yaml"); err == nil {
+		t.Fatalf("Expected an error for a missing versions file")
+	}
+}
+
+func TestNewDatasetInvalidVersion(t *testing.T) {
+	v := &Versions{DeprecatedVersions: []*Version{
+		{APIVersion: "apps/v1beta1", Kind: "Deployment", RemovedInVersion: "one.sixteen"},
+	}}
+	if _, err := NewDataset(v); err == nil {
+		t.Fatalf("Expected an error for an invalid removedInVersion")
+	}
+}
+
+func TestNewDatasetInvalidSeverity(t *testing.T) {
+	v := &Versions{DeprecatedVersions: []*Version{
+		{APIVersion: "apps/v1beta1", Kind: "Deployment", RemovedInVersion: "v1.16.0", Severity: "urgent"},
+	}}
+	if _, err := NewDataset(v); err == nil {
+		t.Fatalf("Expected an error for an invalid severity")
+	}
+}
+
+func TestMaxSeverity(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected string
+	}{
+		{"", "", ""},
+		{"", RuleSeverityInfo, RuleSeverityInfo},
+		{RuleSeverityWarning, RuleSeverityInfo, RuleSeverityWarning},
+		{RuleSeverityWarning, RuleSeverityCritical, RuleSeverityCritical},
+		{RuleSeverityCritical, "", RuleSeverityCritical},
+	}
+	for _, c := range cases {
+		if got := MaxSeverity(c.a, c.b); got != c.expected {
+			t.Fatalf("Expected the most urgent of %q and %q to be %q, got: %q", c.a, c.b, c.expected, got)
+		}
+	}
+}
+
+func TestNewDatasetDuplicates(t *testing.T) {
+	v := &Versions{DeprecatedVersions: []*Version{
+		{APIVersion: "apps/v1beta1", Kind: "Deployment", RemovedInVersion: "v1.16.0"},
+		{APIVersion: "apps/v1beta1", Kind: "Deployment", RemovedInVersion: "v1.18.0"},
+	}}
+	d, err := NewDataset(v)
+	if err != nil {
+		t.Fatalf("Unexpected error creating the dataset: %v", err)
+	}
+	got, _ := d.Check("Deployment", "apps/v1beta1", "v1.16.0")
+	if !got.Removed || d.Len() != 1 {
+		t.Fatalf("Expected the first entry of a duplicated API version to be used")
+	}
+}
+
+func TestDatasetCheckCoreGroup(t *testing.T) {
+	v := &Versions{DeprecatedVersions: []*Version{
+		{APIVersion: "v1", Kind: "ComponentStatus", DeprecatedInVersion: "v1.19.0"},
+	}}
+	d, _ := NewDataset(v)
+	got, err := d.Check("ComponentStatus", "v1", "v1.19.0")
+	if err != nil || !got.Known || !got.Deprecated {
+		t.Fatalf("Expected the core group API version to be deprecated, got: %+v, %v", got, err)
+	}
+}
+
+func TestDatasetMatchKinds(t *testing.T) {
+	v := &Versions{DeprecatedVersions: []*Version{
+		{APIVersion: "flowcontrol.apiserver.k8s.io/v1beta1", Kind: "*", RemovedInVersion: "v1.26.0"},
+		{APIVersion: "flowcontrol.apiserver.k8s.io/v1beta1", Kind: "Flow*", RemovedInVersion: "v1.25.0"},
+		{APIVersion: "flowcontrol.apiserver.k8s.io/v1beta1", Kind: "FlowSchema", RemovedInVersion: "v1.24.0"},
+		{APIVersion: "flowcontrol.apiserver.k8s.io/v1beta1", Kind: "FlowSchemaList", RemovedInVersion: "v1.23.0"},
+		{APIVersion: "rbac.authorization.k8s.io/v1beta1", Kind: "Role", RemovedInVersion: "v1.22.0"},
+		{APIVersion: "autoscaling/v2beta1", Kind: "*", RemovedInVersion: "v1.25.0", Component: "other"},
+	}}
+	d, err := NewDataset(v)
+	if err != nil {
+		t.Fatalf("Unexpected error creating the dataset: %v", err)
+	}
+	tests := []struct {
+		kind       string
+		apiVersion string
+		removedIn  string
+	}{
+		{kind: "FlowSchemaList", apiVersion: "flowcontrol.apiserver.k8s.io/v1beta1", removedIn: "v1.23.0"},
+		{kind: "FlowSchema", apiVersion: "flowcontrol.apiserver.k8s.io/v1beta1", removedIn: "v1.24.0"},
+		{kind: "FlowControl", apiVersion: "flowcontrol.apiserver.k8s.io/v1beta1", removedIn: "v1.25.0"},
+		{kind: "PriorityLevelConfiguration", apiVersion: "flowcontrol.apiserver.k8s.io/v1beta1", removedIn: "v1.26.0"},
+		{kind: "PriorityLevelConfigurationList", apiVersion: "flowcontrol.apiserver.k8s.io/v1beta1", removedIn: "v1.26.0"},
+		{kind: "RoleList", apiVersion: "rbac.authorization.k8s.io/v1beta1", removedIn: "v1.22.0"},
+		{kind: "RoleBindingList", apiVersion: "rbac.authorization.k8s.io/v1beta1"},
+		{kind: "List", apiVersion: "rbac.authorization.k8s.io/v1beta1"},
+		{kind: "HorizontalPodAutoscaler", apiVersion: "autoscaling/v2beta1", removedIn: "v1.25.0"},
+		{kind: "FlowSchema", apiVersion: "flowcontrol.apiserver.k8s.io/v1beta2"},
+	}
+	for _, tt := range tests {
+		r := d.lookup("", tt.kind, tt.apiVersion)
+		got := ""
+		if r != nil {
+			got = r.RemovedInVersion
+		}
+		if got != tt.removedIn {
+			t.Fatalf("%s %s: expected the rule removed in %q, got %q", tt.apiVersion, tt.kind, tt.removedIn, got)
+		}
+	}
+
+	if r := d.lookup(KubernetesComponent, "HorizontalPodAutoscaler", "autoscaling/v2beta1"); r != nil {
+		t.Fatalf("Expected the rule of another component not to match, got: %+v", r.Version)
+	}
+	if _, err := NewDataset(&Versions{DeprecatedVersions: []*Version{{APIVersion: "apps/v1beta1", Kind: "[Deployment"}}}); err == nil {
+		t.Fatalf("Expected an error for an invalid kind pattern")
+	}
+}
+
+// syntheticVersions returns a list of n unique deprecated API versions.
+func syntheticVersions(n int) *Versions {
+	v := new(Versions)
+	for i := 0; i < n; i++ {
+		v.DeprecatedVersions = append(v.DeprecatedVersions, &Version{
+			APIVersion:          fmt.Sprintf("group%d.example.com/v1beta1", i),
+			Kind:                fmt.Sprintf("Kind%d", i),
+			DeprecatedInVersion: "v1.20.0",
+			RemovedInVersion:    "v1.24.0",
+			ReplacementAPI:      fmt.Sprintf("group%d.example.com/v1", i),
+		})
+	}
+	return v
+}
+
+func BenchmarkDatasetCheck(b *testing.B) {
+	for _, n := range []int{10, 1000, 100000} {
+		d, err := NewDataset(syntheticVersions(n))
+		if err != nil {
+			b.Fatal(err)
+		}
+		last := d.Versions().DeprecatedVersions[n-1]
+		b.Run(fmt.Sprintf("entries=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := d.Check(last.Kind, last.APIVersion, "v1.22.0"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkCheckDeprecations(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := CheckDeprecations("Ingress", "extensions/v1beta1", "v1.22.0", versionsFile); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestDatasetCheckComponent(t *testing.T) {
+	v := &Versions{DeprecatedVersions: []*Version{
+		{APIVersion: "cert-manager.io/v1alpha2", Kind: "Certificate", DeprecatedInVersion: "v1.4.0", RemovedInVersion: "v1.6.0", ReplacementAPI: "cert-manager.io/v1", Component: "cert-manager"},
+		{APIVersion: "example.com/v1beta1", Kind: "Widget", RemovedInVersion: "v2.0.0", Component: "widgets"},
+		{APIVersion: "example.com/v1beta1", Kind: "Widget", RemovedInVersion: "v1.25.0", Component: "k8s"},
+	}}
+	d, err := NewDataset(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions := ComponentVersions{KubernetesComponent: "v1.25.0", "cert-manager": "v1.5.3", "widgets": "v1.9.0"}
+
+	apis := []struct {
+		component  string
+		kind       string
+		apiVersion string
+		matched    string
+		removed    bool
+	}{
+		{"cert-manager", "Certificate", "cert-manager.io/v1alpha2", "cert-manager", false},
+		{"", "Certificate", "cert-manager.io/v1alpha2", "cert-manager", false},
+		{"k8s", "Certificate", "cert-manager.io/v1alpha2", "", false},
+		{"", "Widget", "example.com/v1beta1", "k8s", true},
+		{"widgets", "Widget", "example.com/v1beta1", "widgets", false},
+	}
+	for _, api := range apis {
+		got, err := d.CheckComponent(api.component, api.kind, api.apiVersion, versions)
+		if err != nil {
+			t.Fatalf("Unexpected error checking the API Version: %v, %v", api.apiVersion, err)
+		}
+		if got.Known != (api.matched != "") || (got.Known && got.Component != api.matched) {
+			t.Fatalf("The API Version: %v of component: %q matched: %q. Expected: %q", api.apiVersion, api.component, got.Component, api.matched)
+		}
+		if got.Removed != api.removed {
+			t.Fatalf("The API Version: %v of component: %q removal status is: %v. Expected: %v", api.apiVersion, api.component, got.Removed, api.removed)
+		}
+	}
+
+	got, err := d.CheckComponent("", "Certificate", "cert-manager.io/v1alpha2", ComponentVersions{KubernetesComponent: "v1.25.0"})
+	var unknown *UnknownComponentVersionError
+	if !errors.As(err, &unknown) || unknown.Component != "cert-manager" {
+		t.Fatalf("Expected an unknown component version error, got: %v", err)
+	}
+	if !got.Known || got.ReplacementAPI() != "cert-manager.io/v1" || got.ComponentVersion != nil {
+		t.Fatalf("Expected the rule information without the status, got: %+v", got)
+	}
+
+	got, err = d.CheckComponent("", "Certificate", "cert-manager.io/v1alpha2", ComponentVersions{"cert-manager": "latest"})
+	if !errors.As(err, &unknown) || unknown.Component != "cert-manager" || unknown.Version != "latest" || unknown.Err == nil {
+		t.Fatalf("Expected an invalid detected version to be an unknown component version, got: %v", err)
+	}
+	if got == nil || !got.Known || got.ComponentVersion != nil {
+		t.Fatalf("Expected the rule information without the status, got: %+v", got)
+	}
+}
+
+func TestDefault(t *testing.T) {
+	d, err := LoadDataset(versionsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Default().Len() != d.Len() || Default().Revision() != d.Revision() {
+		t.Fatalf("Expected the embedded dataset to match the versions file, got revision %s, expected %s", Default().Revision(), d.Revision())
+	}
+}
+
+func TestDatasetRevision(t *testing.T) {
+	v := &Versions{DeprecatedVersions: []*Version{
+		{APIVersion: "apps/v1beta1", Kind: "Deployment", RemovedInVersion: "v1.16.0"},
+	}}
+	d1, _ := NewDataset(v)
+	d2, _ := NewDataset(v)
+	if d1.Revision() == "" || d1.Revision() != d2.Revision() {
+		t.Fatalf("Expected the same revision for the same API versions, got %q and %q", d1.Revision(), d2.Revision())
+	}
+
+	changed, _ := NewDataset(&Versions{DeprecatedVersions: []*Version{
+		{APIVersion: "apps/v1beta1", Kind: "Deployment", RemovedInVersion: "v1.17.0"},
+	}})
+	if changed.Revision() == d1.Revision() {
+		t.Fatalf("Expected a different revision after changing a rule")
+	}
+}
+
+func TestDatasetCheckReleasesUntilRemoval(t *testing.T) {
+	v := &Versions{DeprecatedVersions: []*Version{
+		{APIVersion: "batch/v1beta1", Kind: "CronJob", DeprecatedInVersion: "v1.21.0", RemovedInVersion: "v1.25.0"},
+		{APIVersion: "autoscaling/v2beta1", Kind: "HorizontalPodAutoscaler", DeprecatedInVersion: "v1.22.0"},
+		{APIVersion: "example.com/v1alpha1", Kind: "Example", RemovedInVersion: "v2.0.0"},
+	}}
+	d, _ := NewDataset(v)
+	tests := []struct {
+		kind       string
+		apiVersion string
+		k8sVersion string
+		expected   string
+		within     bool
+	}{
+		{kind: "CronJob", apiVersion: "batch/v1beta1", k8sVersion: "v1.21.3", expected: "4"},
+		{kind: "CronJob", apiVersion: "batch/v1beta1", k8sVersion: "v1.23.0", expected: "2", within: true},
+		{kind: "CronJob", apiVersion: "batch/v1beta1", k8sVersion: "v1.26.0", expected: "0", within: true},
+		{kind: "HorizontalPodAutoscaler", apiVersion: "autoscaling/v2beta1", k8sVersion: "v1.23.0", expected: "nil"},
+		{kind: "Example", apiVersion: "example.com/v1alpha1", k8sVersion: "v1.23.0", expected: "nil"},
+	}
+	for _, tt := range tests {
+		got, err := d.Check(tt.kind, tt.apiVersion, tt.k8sVersion)
+		if err != nil {
+			t.Fatal(err)
+		}
+		releases := "nil"
+		if got.ReleasesUntilRemoval != nil {
+			releases = fmt.Sprint(*got.ReleasesUntilRemoval)
+		}
+		if releases != tt.expected || got.RemovedWithin(2) != tt.within {
+			t.Fatalf("%s %s on %s: expected %s releases until removal (within 2: %t), got %s (%t)",
+				tt.apiVersion, tt.kind, tt.k8sVersion, tt.expected, tt.within, releases, got.RemovedWithin(2))
+		}
+	}
+}
+
+func TestDatasetCheckNotYetAvailable(t *testing.T) {
+	v := &Versions{DeprecatedVersions: []*Version{
+		{APIVersion: "autoscaling/v2", Kind: "HorizontalPodAutoscaler", IntroducedInVersion: "v1.23.0"},
+		{APIVersion: "autoscaling/v2beta2", Kind: "HorizontalPodAutoscaler", IntroducedInVersion: "v1.12.0", DeprecatedInVersion: "v1.23.0", RemovedInVersion: "v1.26.0"},
+	}}
+	d, err := NewDataset(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		apiVersion      string
+		k8sVersion      string
+		notYetAvailable bool
+	}{
+		{apiVersion: "autoscaling/v2", k8sVersion: "v1.22.9", notYetAvailable: true},
+		{apiVersion: "autoscaling/v2", k8sVersion: "v1.23.0"},
+		{apiVersion: "autoscaling/v2beta2", k8sVersion: "v1.11.0", notYetAvailable: true},
+		{apiVersion: "autoscaling/v2beta2", k8sVersion: "v1.26.0"},
+	}
+	for _, tt := range tests {
+		got, err := d.Check("HorizontalPodAutoscaler", tt.apiVersion, tt.k8sVersion)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.NotYetAvailable != tt.notYetAvailable {
+			t.Fatalf("%s on %s: expected not yet available %t, got %t", tt.apiVersion, tt.k8sVersion, tt.notYetAvailable, got.NotYetAvailable)
+		}
+	}
+	if _, err := NewDataset(&Versions{DeprecatedVersions: []*Version{{APIVersion: "autoscaling/v2", Kind: "HorizontalPodAutoscaler", IntroducedInVersion: "v1.x"}}}); err == nil {
+		t.Fatalf("Expected an invalid introducedInVersion to fail")
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deprecation
+
+import (
+	_ "embed"
+	"sync"
+)
+
+// defaultVersionsFile is the versions file compiled into the binary.
+//
+//go:embed versions.yaml
+var defaultVersionsFile []byte
+
+// defaultCalendarFile is the release calendar compiled into the binary.
+//
+//go:embed calendar.yaml
+var defaultCalendarFile []byte
+
+var (
+	defaultOnce    sync.Once
+	defaultDataset *Dataset
+
+	defaultCalendarOnce sync.Once
+	defaultCalendar     *Calendar
+)
+
+// Default returns the Dataset of the versions file compiled into the binary.
+// The embedded versions file is checked by the tests, so it panics only when the binary is built from an invalid one.
+func Default() *Dataset {
+	defaultOnce.Do(func() {
+		d, err := ParseDataset(defaultVersionsFile)
+		if err != nil {
+			panic("invalid embedded versions file: " + err.Error())
+		}
+		defaultDataset = d
+	})
+	return defaultDataset
+}
+
+// DefaultVersionsFile returns the content of the versions file compiled into the binary.
+func DefaultVersionsFile() []byte {
+	return append([]byte(nil), defaultVersionsFile...)
+}
+
+// DefaultCalendar returns the upstream release calendar compiled into the binary.
+// The embedded calendar is checked by the tests, so it panics only when the binary is built from an invalid one.
+func DefaultCalendar() *Calendar {
+	defaultCalendarOnce.Do(func() {
+		c, err := ParseCalendar(defaultCalendarFile)
+		if err != nil {
+			panic("invalid embedded calendar: " + err.Error())
+		}
+		defaultCalendar = c
+	})
+	return defaultCalendar
+}
@@ -3,61 +3,11 @@ package deprecation
 import (
 	"reflect"
 	"testing"
-)
-
-var versionsFile string = "../../config/versions.yaml"
-
-func TestIsNewerOrEqualVersio(t *testing.T) {
-	cases := []struct {
-		v1       string
-		v2       string
-		expected bool
-	}{
-		{"v1.7rc2", "1.22.0", false},
-		{"1.0-7rc2", "v1.22.0-alpha.1", false},
-		{"1.13.0", "1.14.0", false},
-		{"v1.12.0", "1.14.0", false},
-		{"v1.9.0-alpha.1", "1.10.0", false},
-		{"v1.25.0-alpha.3", "1.23.0", true},
-		{"v1.26.0-rc.0", "1.22.0", true},
-		{"v1.22.0-alpha.1", "1.25.0", false},
-		{"v1.22.0-alpha.1", "1.2.0-x.Y.0+metadata", true},
-	}
-
-	for _, c := range cases {
-		var got bool
-		got, _ = isNewerOrEqualVersion(c.v1, c.v2)
-		if got != c.expected {
-			t.Fatalf("Expected version: %v to be newer or equal than version: %v", c.v1, c.v2)
-
-		}
-	}
-
-}
 
-func TestIncrementSemVer(t *testing.T) {
-	versions := []struct {
-		version            string
-		steps              int
-		incrementedVersion string
-	}{
-		{"1.13.0", 1, "1.14.0"},
-		{"v1.12.0", 2, "1.14.0"},
-		{"v1.9.0-alpha.1", 1, "1.10.0"},
-		{"v1.20.0-alpha.3", 3, "1.23.0"},
-		{"v1.20.0-rc.0", 2, "1.22.0"},
-		{"v1.22.0-alpha.1", 3, "1.25.0"},
-	}
+	semver "github.com/hashicorp/go-version"
+)
 
-	for _, v := range versions {
-		var got string
-		got, _ = incrementSemVer(v.version, v.steps)
-		if got != v.incrementedVersion {
-			t.Fatalf("Version: %v is expected to be: %v after incrementing by %d steps, but got: %v", v.version, v.incrementedVersion, v.steps, got)
-
-		}
-	}
-}
+var versionsFile string = "versions.yaml"
 
 func TestIsDeprecatedVersion(t *testing.T) {
 	apis := []struct {
@@ -79,10 +29,12 @@ func TestIsDeprecatedVersion(t *testing.T) {
 	}
 
 	for _, api := range apis {
-		var got bool
-		got = isDeprecatedVersion(api.kind, api.apiVersion, api.k8sVersion, versionsFile)
-		if got != api.status {
-			t.Fatalf("The API Version: %v deprecation status is: %v. Expected: %v", api.apiVersion, api.status, got)
+		got, err := CheckDeprecations(api.kind, api.apiVersion, api.k8sVersion, versionsFile)
+		if err != nil {
+			t.Fatalf("Unexpected error checking the API Version: %v, %v", api.apiVersion, err)
+		}
+		if got.Deprecated != api.status {
+			t.Fatalf("The API Version: %v deprecation status is: %v. Expected: %v", api.apiVersion, got.Deprecated, api.status)
 
 		}
 	}
@@ -107,85 +59,94 @@ func TestIsRemovedVersion(t *testing.T) {
 		{"ReplicaSet", "extensions/v1beta1", "v1.17.0", true},
 		{"PriorityClass", "scheduling.k8s.io/v1beta1", "v1.17.0", true},
 		{"PodDisruptionBudgetList", "policy/v1beta1", "v1.17.0", false},
+		{"Deployment", "apps/v1beta2", "v1.16.0-gke.6000", true},
+		{"Ingress", "extensions/v1beta1", "v1.22.0-eks-8cb36c9", true},
+		{"Ingress", "extensions/v1beta1", "v1.22.0+f67aeb3", true},
 	}
 
 	for _, api := range apis {
-		var got bool
-		got = isRemovedVersion(api.kind, api.apiVersion, api.k8sVersion, versionsFile)
-		if got != api.status {
-			t.Fatalf("The API Version: %v removal status is: %v. Expected: %v", api.apiVersion, api.status, got)
+		got, err := CheckDeprecations(api.kind, api.apiVersion, api.k8sVersion, versionsFile)
+		if err != nil {
+			t.Fatalf("Unexpected error checking the API Version: %v, %v", api.apiVersion, err)
+		}
+		if got.Removed != api.status {
+			t.Fatalf("The API Version: %v removal status is: %v. Expected: %v", api.apiVersion, got.Removed, api.status)
 
 		}
 	}
 
 }
 
-func TestGetDeprecatedKindInfo(t *testing.T) {
+func TestDeprecatedKindInfo(t *testing.T) {
 	versions := []struct {
-		kind       string
-		apiVersion string
-		expected   map[string]string
+		kind                string
+		apiVersion          string
+		replacementApi      string
+		removedInVersion    string
+		deprecatedInVersion string
 	}{
-		{
-			"Deployment",
-			"extensions/v1beta1",
-			map[string]string{
-				"replacementApi":      "apps/v1",
-				"removedInVersion":    "v1.16.0",
-				"deprecatedInVersion": "v1.9.0",
-			},
-		},
-		{
-			"StatefulSet",
-			"apps/v1beta1",
-			map[string]string{
-				"replacementApi":      "apps/v1",
-				"removedInVersion":    "v1.16.0",
-				"deprecatedInVersion": "v1.9.0",
-			},
-		},
-		{
-			"PodDisruptionBudget",
-			"policy/v1beta1",
-			map[string]string{
-				"replacementApi":      "n/a",
-				"removedInVersion":    "n/a",
-				"deprecatedInVersion": "v1.22.0",
-			},
-		},
+		{"Deployment", "extensions/v1beta1", "apps/v1", "v1.16.0", "v1.9.0"},
+		{"StatefulSet", "apps/v1beta1", "apps/v1", "v1.16.0", "v1.9.0"},
+		{"PodDisruptionBudget", "policy/v1beta1", "policy/v1", "v1.25.0", "v1.22.0"},
+		{"Deployment", "apps/v1", "n/a", "n/a", "n/a"},
 	}
 
 	for _, v := range versions {
-		var got map[string]string
-		got = getDeprecatedKindInfo(v.kind, v.apiVersion, versionsFile)
-		if !reflect.DeepEqual(got, v.expected) {
-			t.Fatalf("The API Version info: %v doesn't match the expected result, \nExpected: %v. ", got, v.expected)
+		got, err := CheckDeprecations(v.kind, v.apiVersion, "v1.20.0", versionsFile)
+		if err != nil {
+			t.Fatalf("Unexpected error checking the API Version: %v, %v", v.apiVersion, err)
+		}
+		info := []string{got.ReplacementAPI(), FormatVersion(got.RemovedInVersion), FormatVersion(got.DeprecatedInVersion)}
+		expected := []string{v.replacementApi, v.removedInVersion, v.deprecatedInVersion}
+		if !reflect.DeepEqual(info, expected) {
+			t.Fatalf("The API Version info: %v doesn't match the expected result, \nExpected: %v. ", info, expected)
 
 		}
 	}
 }
 
 func TestCheckDeprecations(t *testing.T) {
+	deploymentFieldChanges := []string{
+		"spec.rollbackTo is removed",
+		"spec.selector is required and immutable after creation",
+		"spec.progressDeadlineSeconds defaults to 600 seconds",
+		"spec.revisionHistoryLimit defaults to 10",
+		"spec.strategy.rollingUpdate.maxSurge and maxUnavailable default to 25%",
+	}
+	ingressFieldChanges := []string{
+		"spec.backend is renamed to spec.defaultBackend",
+		"the backend serviceName is renamed to service.name",
+		"the backend servicePort is renamed to service.port.number for numeric ports and service.port.name for named ports",
+		"pathType is required for each path",
+	}
 	versions := []struct {
 		kind       string
 		apiVersion string
 		k8sVersion string
-		expected   map[string]string
+		expected   *Result
 	}{
 		{
 			"Deployment",
 			"extensions/v1beta1",
 			"v1.16.0",
-			map[string]string{
-				"apiVersion":               "extensions/v1beta1",
-				"kind":                     "Deployment",
-				"deprecated":               "true",
-				"removed":                  "true",
-				"replacementApi":           "apps/v1",
-				"removedInVersion":         "v1.16.0",
-				"deprecatedInVersion":      "v1.9.0",
-				"removedInNextRelease":     "true",
-				"removedInNextTwoReleases": "true",
+			&Result{
+				APIVersion:               "extensions/v1beta1",
+				Kind:                     "Deployment",
+				Component:                "k8s",
+				ComponentVersion:         semver.Must(semver.NewVersion("v1.16.0")),
+				Known:                    true,
+				Deprecated:               true,
+				Removed:                  true,
+				Replacement:              &Replacement{APIVersion: "apps/v1", Kind: "Deployment"},
+				Severity:                 RuleSeverityCritical,
+				MigrationNote:            "Change the apiVersion to apps/v1 and set spec.selector to the labels of the pod template",
+				MigrationGuide:           "https://kubernetes.io/docs/reference/using-api/deprecation-guide/#v1-16",
+				FieldChanges:             deploymentFieldChanges,
+				RemovedInVersion:         semver.Must(semver.NewVersion("v1.16.0")),
+				DeprecatedInVersion:      semver.Must(semver.NewVersion("v1.9.0")),
+				RemovedInNextRelease:     true,
+				RemovedInNextTwoReleases: true,
+				ReleasesUntilRemoval:     releases(0),
 			},
 		},
 
@@ -193,26 +154,118 @@ func TestCheckDeprecations(t *testing.T) {
 			"Ingress",
 			"extensions/v1beta1",
 			"v1.19.0",
-			map[string]string{
-				"apiVersion":               "extensions/v1beta1",
-				"kind":                     "Ingress",
-				"deprecated":               "true",
-				"removed":                  "false",
-				"replacementApi":           "networking.k8s.io/v1",
-				"removedInVersion":         "v1.22.0",
-				"deprecatedInVersion":      "v1.14.0",
-				"removedInNextRelease":     "false",
-				"removedInNextTwoReleases": "false",
+			&Result{
+				APIVersion:               "extensions/v1beta1",
+				Kind:                     "Ingress",
+				Component:                "k8s",
+				ComponentVersion:         semver.Must(semver.NewVersion("v1.19.0")),
+				Known:                    true,
+				Deprecated:               true,
+				Removed:                  false,
+				Replacement:              &Replacement{APIVersion: "networking.k8s.io/v1", Kind: "Ingress"},
+				Severity:                 RuleSeverityCritical,
+				MigrationNote:            "Change the apiVersion to networking.k8s.io/v1 and update the backends to the service format",
+				MigrationGuide:           "https://kubernetes.io/docs/reference/using-api/deprecation-guide/#ingress-v122",
+				FieldChanges:             ingressFieldChanges,
+				RemovedInVersion:         semver.Must(semver.NewVersion("v1.22.0")),
+				DeprecatedInVersion:      semver.Must(semver.NewVersion("v1.14.0")),
+				RemovedInNextRelease:     false,
+				RemovedInNextTwoReleases: false,
+				ReleasesUntilRemoval:     releases(3),
+			},
+		},
+
+		{
+			"Ingress",
+			"extensions/v1beta1",
+			"v1.20.0",
+			&Result{
+				APIVersion:               "extensions/v1beta1",
+				Kind:                     "Ingress",
+				Component:                "k8s",
+				ComponentVersion:         semver.Must(semver.NewVersion("v1.20.0")),
+				Known:                    true,
+				Deprecated:               true,
+				Removed:                  false,
+				Replacement:              &Replacement{APIVersion: "networking.k8s.io/v1", Kind: "Ingress"},
+				Severity:                 RuleSeverityCritical,
+				MigrationNote:            "Change the apiVersion to networking.k8s.io/v1 and update the backends to the service format",
+				MigrationGuide:           "https://kubernetes.io/docs/reference/using-api/deprecation-guide/#ingress-v122",
+				FieldChanges:             ingressFieldChanges,
+				RemovedInVersion:         semver.Must(semver.NewVersion("v1.22.0")),
+				DeprecatedInVersion:      semver.Must(semver.NewVersion("v1.14.0")),
+				RemovedInNextRelease:     false,
+				RemovedInNextTwoReleases: true,
+				ReleasesUntilRemoval:     releases(2),
+			},
+		},
+
+		{
+			"Deployment",
+			"apps/v1",
+			"v1.20.0",
+			&Result{
+				APIVersion:          "apps/v1",
+				Kind:                "Deployment",
+				Component:           "k8s",
+				ComponentVersion:    semver.Must(semver.NewVersion("v1.20.0")),
+				Known:               true,
+				IntroducedInVersion: semver.Must(semver.NewVersion("v1.9.0")),
+			},
+		},
+
+		{
+			"Pod",
+			"v1",
+			"v1.20.0",
+			&Result{
+				APIVersion: "v1",
+				Kind:       "Pod",
+				Component:  "k8s",
 			},
 		},
 	}
 
 	for _, v := range versions {
-		var got map[string]string
-		got = CheckDeprecations(v.kind, v.apiVersion, v.k8sVersion, versionsFile)
+		got, err := CheckDeprecations(v.kind, v.apiVersion, v.k8sVersion, versionsFile)
+		if err != nil {
+			t.Fatalf("Unexpected error checking the API Version: %v, %v", v.apiVersion, err)
+		}
 		if !reflect.DeepEqual(got, v.expected) {
-			t.Fatalf("CheckDeprecation \nExpected: %v doesn't match the expected result, \nExpected: %v. ", got, v.expected)
+			t.Fatalf("CheckDeprecation \nResult: %+v doesn't match the expected result, \nExpected: %+v. ", got, v.expected)
 
 		}
 	}
 }
+
+func TestCheckDeprecationsErrors(t *testing.T) {
+	if _, err := CheckDeprecations("Deployment", "extensions/v1beta1", "v1.20.0", "does-not-exist.yaml"); err == nil {
+		t.Fatalf("Expected an error for a missing versions file")
+	}
+	if _, err := CheckDeprecations("Deployment", "extensions/v1beta1", "", versionsFile); err == nil {
+		t.Fatalf("Expected an error for an empty Kubernetes version")
+	}
+}
+
+func TestMarshalVersions(t *testing.T) {
+	v, err := getDeprecatedVersions(versionsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := MarshalVersions(v)
+	if err != nil {
+		t.Fatalf("Unexpected error marshaling the versions: %v", err)
+	}
+	got, err := ParseVersions(content)
+	if err != nil {
+		t.Fatalf("Unexpected error parsing the marshaled versions: %v", err)
+	}
+	if !reflect.DeepEqual(got, v) {
+		t.Fatalf("Expected the marshaled versions to be parsed back to the same versions")
+	}
+}
+
+// releases returns a number of releases until removal.
+func releases(n int) *int {
+	return &n
+}
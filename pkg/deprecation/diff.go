@@ -0,0 +1,173 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deprecation
+
+import (
+	"reflect"
+)
+
+// ChangeType is the type of change of a deprecated API version between two versions files.
+type ChangeType string
+
+const (
+	// Added is a deprecated API version which is only in the new versions
+	Added ChangeType = "added"
+	// Removed is a deprecated API version which is only in the old versions
+	Removed ChangeType = "removed"
+	// Modified is a deprecated API version which is in both versions with different fields
+	Modified ChangeType = "modified"
+)
+
+// Change is a deprecated API version which is different between two versions files.
+type Change struct {
+	Type ChangeType
+	// Old is the deprecated API version in the old versions, nil when it's added
+	Old *Version
+	// New is the deprecated API version in the new versions, nil when it's removed
+	New *Version
+}
+
+// Version returns the new deprecated API version, the old one when it's removed.
+func (c Change) Version() *Version {
+	if c.New != nil {
+		return c.New
+	}
+	return c.Old
+}
+
+// Diff returns the deprecated API versions which are added, removed or modified between the from (old)
+// and the to (new) versions, they are matched by component, group, version and kind like in a Dataset.
+// When a versions file lists the same API version more than once, its first entry is used.
+// The changes are in the order of the new versions, followed by the removed ones in the order of the old versions.
+func Diff(from, to *Versions) []Change {
+	oldRules := firstEntries(from)
+	newRules := firstEntries(to)
+
+	var changes []Change
+	for _, v := range entries(to) {
+		key := keyOf(v)
+		if newRules[key] != v {
+			continue
+		}
+		o, ok := oldRules[key]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Type: Added, New: v})
+		case !reflect.DeepEqual(withComponent(o), withComponent(v)):
+			changes = append(changes, Change{Type: Modified, Old: o, New: v})
+		}
+	}
+	for _, v := range entries(from) {
+		key := keyOf(v)
+		if _, ok := newRules[key]; !ok && oldRules[key] == v {
+			changes = append(changes, Change{Type: Removed, Old: v})
+		}
+	}
+	return changes
+}
+
+// FieldChange is a deprecated field or annotation which is different between two versions files.
+type FieldChange struct {
+	Type ChangeType
+	// Old is the deprecated field or annotation in the old versions, nil when it's added
+	Old *FieldVersion
+	// New is the deprecated field or annotation in the new versions, nil when it's removed
+	New *FieldVersion
+}
+
+// FieldVersion returns the new deprecated field or annotation, the old one when it's removed.
+func (c FieldChange) FieldVersion() *FieldVersion {
+	if c.New != nil {
+		return c.New
+	}
+	return c.Old
+}
+
+// DiffFields returns the deprecated fields and annotations which are added, removed or modified between the from
+// (old) and the to (new) versions, like Diff does for the deprecated API versions.
+func DiffFields(from, to *Versions) []FieldChange {
+	oldFields := firstFieldEntries(from)
+	newFields := firstFieldEntries(to)
+
+	var changes []FieldChange
+	for _, f := range fieldEntries(to) {
+		key := fieldKeyOf(f)
+		if newFields[key] != f {
+			continue
+		}
+		o, ok := oldFields[key]
+		switch {
+		case !ok:
+			changes = append(changes, FieldChange{Type: Added, New: f})
+		case !reflect.DeepEqual(withFieldComponent(o), withFieldComponent(f)):
+			changes = append(changes, FieldChange{Type: Modified, Old: o, New: f})
+		}
+	}
+	for _, f := range fieldEntries(from) {
+		key := fieldKeyOf(f)
+		if _, ok := newFields[key]; !ok && oldFields[key] == f {
+			changes = append(changes, FieldChange{Type: Removed, Old: f})
+		}
+	}
+	return changes
+}
+
+// fieldEntries returns the deprecated fields and annotations, nil versions are empty.
+func fieldEntries(v *Versions) []*FieldVersion {
+	if v == nil {
+		return nil
+	}
+	return v.DeprecatedFields
+}
+
+// firstFieldEntries indexes the first entry of every deprecated field and annotation.
+func firstFieldEntries(v *Versions) map[fieldKey]*FieldVersion {
+	index := make(map[fieldKey]*FieldVersion)
+	for _, f := range fieldEntries(v) {
+		if _, ok := index[fieldKeyOf(f)]; !ok {
+			index[fieldKeyOf(f)] = f
+		}
+	}
+	return index
+}
+
+// entries returns the deprecated API versions, nil versions are empty.
+func entries(v *Versions) []*Version {
+	if v == nil {
+		return nil
+	}
+	return v.DeprecatedVersions
+}
+
+// firstEntries indexes the first entry of every deprecated API version.
+func firstEntries(v *Versions) map[ruleKey]*Version {
+	index := make(map[ruleKey]*Version)
+	for _, dep := range entries(v) {
+		if _, ok := index[keyOf(dep)]; !ok {
+			index[keyOf(dep)] = dep
+		}
+	}
+	return index
+}
+
+// withComponent returns a copy of the deprecated API version with its component set, so an empty
+// component is the same as k8s.
+func withComponent(v *Version) Version {
+	c := *v
+	c.Component = componentOf(c.Component)
+	return c
+}
@@ -0,0 +1,51 @@
+package deprecation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	ingress := &Version{APIVersion: "extensions/v1beta1", Kind: "Ingress", DeprecatedInVersion: "v1.14.0", RemovedInVersion: "v1.22.0"}
+	deployment := &Version{APIVersion: "extensions/v1beta1", Kind: "Deployment", RemovedInVersion: "v1.16.0"}
+	cronJob := &Version{APIVersion: "batch/v1beta1", Kind: "CronJob", RemovedInVersion: "v1.25.0", Component: "k8s"}
+	movedIngress := &Version{APIVersion: "extensions/v1beta1", Kind: "Ingress", DeprecatedInVersion: "v1.14.0", RemovedInVersion: "v1.23.0"}
+	certificate := &Version{APIVersion: "cert-manager.io/v1alpha2", Kind: "Certificate", RemovedInVersion: "v1.6.0", Component: "cert-manager"}
+	sameCronJob := &Version{APIVersion: "batch/v1beta1", Kind: "CronJob", RemovedInVersion: "v1.25.0"}
+
+	got := Diff(
+		&Versions{DeprecatedVersions: []*Version{ingress, deployment, cronJob}},
+		&Versions{DeprecatedVersions: []*Version{movedIngress, certificate, sameCronJob, ingress}},
+	)
+	expected := []Change{
+		{Type: Modified, Old: ingress, New: movedIngress},
+		{Type: Added, New: certificate},
+		{Type: Removed, Old: deployment},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Unexpected changes: %+v", got)
+	}
+	if len(Diff(nil, nil)) != 0 {
+		t.Fatalf("Expected no changes between nil versions")
+	}
+}
+
+func TestDiffFields(t *testing.T) {
+	class := &FieldVersion{Kind: "Ingress", Annotation: "kubernetes.io/ingress.class", DeprecatedInVersion: "v1.18.0"}
+	removedClass := &FieldVersion{Kind: "Ingress", Annotation: "kubernetes.io/ingress.class", DeprecatedInVersion: "v1.18.0", RemovedInVersion: "v1.30.0"}
+	backend := &FieldVersion{APIVersion: "extensions/v1beta1", Kind: "Ingress", Field: "spec.backend", RemovedInVersion: "v1.22.0"}
+	seccomp := &FieldVersion{Kind: "*", Annotation: "seccomp.security.alpha.kubernetes.io/pod", DeprecatedInVersion: "v1.19.0", Component: "k8s"}
+	sameSeccomp := &FieldVersion{Kind: "*", Annotation: "seccomp.security.alpha.kubernetes.io/pod", DeprecatedInVersion: "v1.19.0"}
+
+	got := DiffFields(
+		&Versions{DeprecatedFields: []*FieldVersion{class, backend, seccomp}},
+		&Versions{DeprecatedFields: []*FieldVersion{removedClass, sameSeccomp}},
+	)
+	expected := []FieldChange{
+		{Type: Modified, Old: class, New: removedClass},
+		{Type: Removed, Old: backend},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Unexpected changes: %+v", got)
+	}
+}
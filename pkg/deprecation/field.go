@@ -0,0 +1,284 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deprecation
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	semver "github.com/hashicorp/go-version"
+)
+
+// FieldVersion describes a deprecated field or annotation of a kind, such as spec.backend of Ingress or
+// the kubernetes.io/ingress.class annotation. Exactly one of Field and Annotation is set.
+type FieldVersion struct {
+	// APIVersion is the API version of the kind, empty for every API version of the kind
+	APIVersion string `json:"version,omitempty" yaml:"version,omitempty"`
+	// Kind is the Object type such as "Ingress", or a pattern such as "*" matching many kinds
+	Kind string `json:"kind" yaml:"kind" jsonschema:"required"`
+	// Field is the path of the deprecated field, such as "spec.backend"
+	Field string `json:"field,omitempty" yaml:"field,omitempty"`
+	// Annotation is the key of the deprecated annotation, or a pattern such as "container.seccomp.security.alpha.kubernetes.io/*"
+	Annotation string `json:"annotation,omitempty" yaml:"annotation,omitempty"`
+	// Kubernetes version in which the field or annotation is deprecated in
+	DeprecatedInVersion string `json:"deprecatedInVersion" yaml:"deprecatedInVersion"`
+	// Kubernetes version in which the field or annotation is removed in, or not honored anymore
+	RemovedInVersion string `json:"removedInVersion" yaml:"removedInVersion"`
+	// Replacement is the field or annotation to use instead, such as "spec.ingressClassName"
+	Replacement string `json:"replacement,omitempty" yaml:"replacement,omitempty"`
+	// Component is the name of the component serving the kind such as "k8s" or "cert-manager",
+	// its versions are compared with the installed version of the component. Empty means "k8s".
+	Component string `json:"component,omitempty" yaml:"component,omitempty"`
+}
+
+// Name returns the field, or the annotation.
+func (f *FieldVersion) Name() string {
+	if f.Field != "" {
+		return f.Field
+	}
+	return f.Annotation
+}
+
+// fieldKey identifies a field rule of the dataset.
+type fieldKey struct {
+	component  string
+	apiVersion string
+	kind       string
+	field      string
+	annotation string
+}
+
+// fieldKeyOf returns the dataset key of the deprecated field or annotation.
+func fieldKeyOf(f *FieldVersion) fieldKey {
+	return fieldKey{component: componentOf(f.Component), apiVersion: f.APIVersion, kind: f.Kind, field: f.Field, annotation: f.Annotation}
+}
+
+// fieldRule is a deprecated field or annotation with its versions already parsed.
+type fieldRule struct {
+	*FieldVersion
+	// source is the name of the source the rule is loaded from
+	source string
+	// precedence is the position of the source in the merged sources, a later source takes precedence
+	precedence          int
+	deprecatedInVersion *semver.Version
+	removedInVersion    *semver.Version
+}
+
+// newFieldRule parses the versions of the deprecated field or annotation.
+func newFieldRule(f *FieldVersion, source string) (*fieldRule, error) {
+	r := &fieldRule{FieldVersion: f, source: source}
+	if (f.Field == "") == (f.Annotation == "") {
+		return nil, fmt.Errorf("exactly one of field and annotation must be set for %s", f.Kind)
+	}
+	if _, err := path.Match(f.Kind, ""); err != nil {
+		return nil, fmt.Errorf("invalid kind pattern of %s %s: %w", f.Kind, f.Name(), err)
+	}
+	if _, err := path.Match(f.Annotation, ""); err != nil {
+		return nil, fmt.Errorf("invalid annotation pattern of %s %s: %w", f.Kind, f.Name(), err)
+	}
+	var err error
+	if r.deprecatedInVersion, err = parseVersion(f.DeprecatedInVersion); err != nil {
+		return nil, fmt.Errorf("invalid deprecatedInVersion of %s %s: %w", f.Kind, f.Name(), err)
+	}
+	if r.removedInVersion, err = parseVersion(f.RemovedInVersion); err != nil {
+		return nil, fmt.Errorf("invalid removedInVersion of %s %s: %w", f.Kind, f.Name(), err)
+	}
+	return r, nil
+}
+
+// setField adds the field rule to the dataset or replaces the field rule with the same key.
+func (d *Dataset) setField(key fieldKey, r *fieldRule) {
+	if i, ok := d.fieldPosition[key]; ok {
+		d.versions.DeprecatedFields[i] = r.FieldVersion
+		d.fields[i] = r
+		return
+	}
+	d.fieldPosition[key] = len(d.fields)
+	d.versions.DeprecatedFields = append(d.versions.DeprecatedFields, r.FieldVersion)
+	d.fields = append(d.fields, r)
+}
+
+// fieldRank ranks a field rule matching a field or annotation, the lowest rank is the most specific match.
+type fieldRank struct {
+	// component is 0 for a Kubernetes rule, 1 for the rule of another component when any component is checked
+	component int
+	// kind ranks the kind of the rule like Dataset.match: the kind, the item kind of a list kind, a pattern, then "*"
+	kind int
+	// apiVersion is 0 for the rule of the API version, 1 for the rule of every API version of the kind
+	apiVersion int
+	// annotation is 0 for the rule of the annotation or a field, 1 for an annotation pattern
+	annotation int
+	// precedence is the opposite of the precedence of the source, so the later sources rank first
+	precedence int
+}
+
+// less compares the ranks field by field.
+func (a fieldRank) less(b fieldRank) bool {
+	for _, d := range []int{a.component - b.component, a.kind - b.kind, a.apiVersion - b.apiVersion,
+		a.annotation - b.annotation, a.precedence - b.precedence} {
+		if d != 0 {
+			return d < 0
+		}
+	}
+	return false
+}
+
+// match checks if the field rule applies to the field or annotation of the kind of the API version served by
+// the component, and ranks the match. An empty component matches the rule of any component.
+func (r *fieldRule) match(component, kind, apiVersion, field, annotation string) (fieldRank, bool) {
+	rank := fieldRank{precedence: -r.precedence}
+	if component != "" && componentOf(r.Component) != component {
+		return rank, false
+	}
+	if componentOf(r.Component) != KubernetesComponent {
+		rank.component = 1
+	}
+	if r.APIVersion != "" && r.APIVersion != apiVersion {
+		return rank, false
+	}
+	if r.APIVersion == "" {
+		rank.apiVersion = 1
+	}
+	var ok bool
+	if rank.kind, ok = kindRank(r.Kind, kind); !ok {
+		return rank, false
+	}
+	if field != "" {
+		return rank, r.Field == field
+	}
+	if r.Annotation != annotation {
+		rank.annotation = 1
+	}
+	return rank, r.Annotation != "" && matchKind(r.Annotation, annotation)
+}
+
+// kindRank ranks how specifically the kind of a rule matches the kind, the same way Dataset.match does: the kind,
+// the item kind of a list kind, a kind pattern, then "*". It returns false when the kind doesn't match.
+func kindRank(pattern, kind string) (int, bool) {
+	item := strings.TrimSuffix(kind, listSuffix)
+	isList := item != kind && item != ""
+	switch {
+	case pattern == kind:
+		return 0, true
+	case isList && pattern == item:
+		return 1, true
+	case pattern == AnyKind:
+		return 3, true
+	case isKindPattern(pattern) && (matchKind(pattern, kind) || (isList && matchKind(pattern, item))):
+		return 2, true
+	}
+	return 0, false
+}
+
+// FieldResult describes the deprecation status of a field or annotation used by a kind,
+// evaluated against the installed version of the component.
+type FieldResult struct {
+	// Field is the path of the used field, empty for an annotation
+	Field string
+	// Annotation is the key of the used annotation, empty for a field
+	Annotation string
+	// Component is the name of the component serving the kind
+	Component string
+	// ComponentVersion is the installed version of the component the status is checked against,
+	// nil if it's unknown
+	ComponentVersion *semver.Version
+	// Known is false when the field or annotation is not in the dataset,
+	// in that case the rest of the fields keep their zero values.
+	Known bool
+	// Source is the name of the dataset source the matching rule is loaded from
+	Source string
+	// Whether the field or annotation is deprecated or not
+	Deprecated bool
+	// Whether the field or annotation is removed or not
+	Removed bool
+	// Whether the field or annotation will be removed in the next release or not
+	RemovedInNextRelease bool
+	// Whether the field or annotation will be removed in the next two releases or not
+	RemovedInNextTwoReleases bool
+	// ReleasesUntilRemoval is the number of minor releases until the field or annotation is removed, 0 once it's
+	// removed. It's nil when it's not removed in a known release, or not in the same major release.
+	ReleasesUntilRemoval *int
+	// Kubernetes version in which the field or annotation is deprecated in, nil if not set
+	DeprecatedInVersion *semver.Version
+	// Kubernetes version in which the field or annotation is removed in, nil if not set
+	RemovedInVersion *semver.Version
+	// Replacement is the field or annotation to use instead, empty if not set
+	Replacement string
+}
+
+// ReplacementName returns the replacement or "n/a" if there is none.
+func (r *FieldResult) ReplacementName() string {
+	if r.Replacement == "" {
+		return NotAvailable
+	}
+	return r.Replacement
+}
+
+// RemovedWithin checks if the field or annotation is removed in the number of releases, or already removed.
+func (r *FieldResult) RemovedWithin(releases int) bool {
+	return r.ReleasesUntilRemoval != nil && *r.ReleasesUntilRemoval <= releases
+}
+
+// CheckField checks the deprecation status of a field, such as spec.backend, used by the kind of the API version
+// served by the component, like CheckComponent does for the API version.
+func (d *Dataset) CheckField(component, kind, apiVersion, field string, versions ComponentVersions) (*FieldResult, error) {
+	return d.checkField(component, kind, apiVersion, field, "", versions)
+}
+
+// CheckAnnotation checks the deprecation status of an annotation used by the kind of the API version served by
+// the component, like CheckComponent does for the API version.
+func (d *Dataset) CheckAnnotation(component, kind, apiVersion, annotation string, versions ComponentVersions) (*FieldResult, error) {
+	return d.checkField(component, kind, apiVersion, "", annotation, versions)
+}
+
+// checkField checks the deprecation status of the field or annotation against the most specific field rule
+// matching it, see fieldRank. Among the rules ranking the same, the first one is used.
+func (d *Dataset) checkField(component, kind, apiVersion, field, annotation string, versions ComponentVersions) (*FieldResult, error) {
+	result := &FieldResult{Field: field, Annotation: annotation, Component: component}
+	var r *fieldRule
+	var best fieldRank
+	for _, f := range d.fields {
+		if rank, ok := f.match(component, kind, apiVersion, field, annotation); ok && (r == nil || rank.less(best)) {
+			r, best = f, rank
+		}
+	}
+	if r == nil {
+		return result, nil
+	}
+
+	result.Known = true
+	result.Component = componentOf(r.Component)
+	result.Source = r.source
+	result.DeprecatedInVersion = r.deprecatedInVersion
+	result.RemovedInVersion = r.removedInVersion
+	result.Replacement = r.Replacement
+
+	current, err := installedVersion(result.Component, versions)
+	if err != nil {
+		return result, err
+	}
+	result.ComponentVersion = current
+
+	result.Deprecated = isReached(current, r.deprecatedInVersion)
+	result.Removed = isReached(current, r.removedInVersion)
+	result.RemovedInNextRelease = isReached(nextMinor(current, 1), r.removedInVersion)
+	result.RemovedInNextTwoReleases = isReached(nextMinor(current, 2), r.removedInVersion)
+	result.ReleasesUntilRemoval = releasesUntil(current, r.removedInVersion)
+
+	return result, nil
+}
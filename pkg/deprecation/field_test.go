@@ -0,0 +1,152 @@
+package deprecation
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDatasetCheckField(t *testing.T) {
+	cases := []struct {
+		kind        string
+		apiVersion  string
+		field       string
+		annotation  string
+		k8sVersion  string
+		known       bool
+		deprecated  bool
+		removed     bool
+		replacement string
+	}{
+		{"Ingress", "extensions/v1beta1", "spec.backend", "", "v1.21.0", true, true, false, "spec.defaultBackend"},
+		{"Ingress", "networking.k8s.io/v1beta1", "spec.backend", "", "v1.22.0", true, true, true, "spec.defaultBackend"},
+		{"Ingress", "networking.k8s.io/v1", "spec.backend", "", "v1.22.0", false, false, false, NotAvailable},
+		{"Ingress", "networking.k8s.io/v1", "spec.defaultBackend", "", "v1.22.0", false, false, false, NotAvailable},
+		{"Ingress", "networking.k8s.io/v1", "", "kubernetes.io/ingress.class", "v1.17.0", true, false, false, "spec.ingressClassName"},
+		{"Ingress", "extensions/v1beta1", "", "kubernetes.io/ingress.class", "v1.18.0", true, true, false, "spec.ingressClassName"},
+		{"IngressClass", "networking.k8s.io/v1", "", "kubernetes.io/ingress.class", "v1.18.0", false, false, false, NotAvailable},
+		{"Deployment", "apps/v1", "", "seccomp.security.alpha.kubernetes.io/pod", "v1.27.0", true, true, true, "spec.securityContext.seccompProfile"},
+		{"Pod", "v1", "", "container.seccomp.security.alpha.kubernetes.io/nginx", "v1.26.0", true, true, false, "spec.containers[*].securityContext.seccompProfile"},
+		{"Pod", "v1", "", "container.apparmor.security.beta.kubernetes.io/nginx", "v1.29.0", true, false, false, "spec.containers[*].securityContext.appArmorProfile"},
+		{"Pod", "v1", "spec.serviceAccountName", "", "v1.29.0", false, false, false, NotAvailable},
+	}
+
+	for _, c := range cases {
+		var got *FieldResult
+		var err error
+		versions := ComponentVersions{KubernetesComponent: c.k8sVersion}
+		if c.field != "" {
+			got, err = Default().CheckField("", c.kind, c.apiVersion, c.field, versions)
+		} else {
+			got, err = Default().CheckAnnotation("", c.kind, c.apiVersion, c.annotation, versions)
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error checking %v %v %v%v: %v", c.apiVersion, c.kind, c.field, c.annotation, err)
+		}
+		if got.Known != c.known || got.Deprecated != c.deprecated || got.Removed != c.removed || got.ReplacementName() != c.replacement {
+			t.Fatalf("Unexpected status of %v %v %v%v in %v: %+v", c.apiVersion, c.kind, c.field, c.annotation, c.k8sVersion, got)
+		}
+	}
+}
+
+func TestDatasetCheckFieldComponent(t *testing.T) {
+	d, err := NewDataset(&Versions{DeprecatedFields: []*FieldVersion{
+		{Kind: "Certificate", Field: "spec.keyAlgorithm", DeprecatedInVersion: "v0.11.0", RemovedInVersion: "v1.0.0",
+			Replacement: "spec.privateKey.algorithm", Component: "cert-manager"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := d.CheckField("", "Certificate", "cert-manager.io/v1alpha2", "spec.keyAlgorithm", ComponentVersions{KubernetesComponent: "v1.22.0"})
+	var unknown *UnknownComponentVersionError
+	if !errors.As(err, &unknown) || unknown.Component != "cert-manager" || !got.Known || got.Deprecated {
+		t.Fatalf("Expected the field to be returned without its status, got: %+v, %v", got, err)
+	}
+	got, err = d.CheckField("", "Certificate", "cert-manager.io/v1alpha2", "spec.keyAlgorithm", ComponentVersions{"cert-manager": "v1.0.0"})
+	if err != nil || !got.Removed || got.ReleasesUntilRemoval == nil || *got.ReleasesUntilRemoval != 0 {
+		t.Fatalf("Expected the field to be removed in cert-manager v1.0.0, got: %+v, %v", got, err)
+	}
+	if got, _ := d.CheckField(KubernetesComponent, "Certificate", "cert-manager.io/v1alpha2", "spec.keyAlgorithm", nil); got.Known {
+		t.Fatalf("Expected the field of another component not to match, got: %+v", got)
+	}
+}
+
+func TestDatasetCheckFieldPrecedence(t *testing.T) {
+	d, _, err := Merge(
+		Source{Name: "default", Versions: &Versions{DeprecatedFields: []*FieldVersion{
+			{Kind: "*", Annotation: "example.com/*", DeprecatedInVersion: "v1.20.0"},
+			{Kind: "*", Annotation: "example.com/mode", DeprecatedInVersion: "v1.21.0"},
+			{Kind: "Deploy*", Annotation: "example.com/mode", DeprecatedInVersion: "v1.22.0"},
+			{Kind: "Pod", Annotation: "example.com/mode", DeprecatedInVersion: "v1.23.0"},
+			{Kind: "Pod", APIVersion: "v1", Annotation: "example.com/mode", DeprecatedInVersion: "v1.24.0"},
+		}}},
+		Source{Name: "override", Versions: &Versions{DeprecatedFields: []*FieldVersion{
+			{Kind: "*", Annotation: "example.com/mode", DeprecatedInVersion: "v1.25.0"},
+		}}},
+		Source{Name: "cluster", Versions: &Versions{DeprecatedFields: []*FieldVersion{
+			{Kind: "Pod", Annotation: "example.com/mode", DeprecatedInVersion: "v1.26.0"},
+		}}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		kind       string
+		apiVersion string
+		annotation string
+		deprecated string
+		source     string
+	}{
+		// the rule of the API version and kind is the most specific one, even when it's loaded last
+		{"Pod", "v1", "example.com/mode", "v1.24.0", "default"},
+		// then the rule of the kind, from the source with the highest precedence
+		{"Pod", "v2", "example.com/mode", "v1.26.0", "cluster"},
+		{"PodList", "v2", "example.com/mode", "v1.26.0", "cluster"},
+		// then a kind pattern, then "*"
+		{"Deployment", "apps/v1", "example.com/mode", "v1.22.0", "default"},
+		{"Service", "v1", "example.com/mode", "v1.25.0", "override"},
+		// an annotation pattern ranks after the annotation
+		{"Service", "v1", "example.com/other", "v1.20.0", "default"},
+	}
+	for _, c := range cases {
+		got, err := d.CheckAnnotation("", c.kind, c.apiVersion, c.annotation, ComponentVersions{KubernetesComponent: "v1.30.0"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if FormatVersion(got.DeprecatedInVersion) != c.deprecated || got.Source != c.source {
+			t.Fatalf("Expected %v %v %v to match the rule deprecated in %v of %q, got: %+v", c.apiVersion, c.kind, c.annotation,
+				c.deprecated, c.source, got)
+		}
+	}
+}
+
+func TestMergeFields(t *testing.T) {
+	class := &FieldVersion{Kind: "Ingress", Annotation: "kubernetes.io/ingress.class", DeprecatedInVersion: "v1.18.0"}
+	removedClass := &FieldVersion{Kind: "Ingress", Annotation: "kubernetes.io/ingress.class", DeprecatedInVersion: "v1.18.0", RemovedInVersion: "v1.30.0"}
+	d, conflicts, err := Merge(
+		Source{Name: "default", Versions: &Versions{DeprecatedFields: []*FieldVersion{class}}},
+		Source{Name: "override", Versions: &Versions{DeprecatedFields: []*FieldVersion{removedClass}}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 1 || conflicts[0].Field != "kubernetes.io/ingress.class" || conflicts[0].Used != "override" {
+		t.Fatalf("Unexpected conflicts: %+v", conflicts)
+	}
+	if len(d.Versions().DeprecatedFields) != 1 || d.Versions().DeprecatedFields[0] != removedClass {
+		t.Fatalf("Expected the override to replace the field, got: %+v", d.Versions().DeprecatedFields)
+	}
+	got, _ := d.CheckAnnotation("", "Ingress", "networking.k8s.io/v1", "kubernetes.io/ingress.class", ComponentVersions{KubernetesComponent: "v1.30.0"})
+	if !got.Removed || got.Source != "override" {
+		t.Fatalf("Expected the overridden annotation to be removed, got: %+v", got)
+	}
+
+	for _, invalid := range []*FieldVersion{
+		{Kind: "Ingress"},
+		{Kind: "Ingress", Field: "spec.backend", Annotation: "kubernetes.io/ingress.class"},
+		{Kind: "Ingress", Field: "spec.backend", RemovedInVersion: "one.twenty-two"},
+	} {
+		if _, err := NewDataset(&Versions{DeprecatedFields: []*FieldVersion{invalid}}); err == nil {
+			t.Fatalf("Expected an error for the invalid field %+v", invalid)
+		}
+	}
+}
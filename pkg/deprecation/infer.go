@@ -0,0 +1,143 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deprecation
+
+import (
+	"fmt"
+	"regexp"
+
+	semver "github.com/hashicorp/go-version"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// lifecycle is the number of minor releases after which a pre-GA Kubernetes API version is deprecated,
+// and the number of minor releases after its deprecation it's removed.
+type lifecycle struct {
+	// since is the first release whose new API versions follow the policy, nil when all of them do
+	since           *semver.Version
+	deprecatedAfter int
+	removedAfter    int
+	// unstable is true when the API versions can be removed in any release without deprecation,
+	// so there's no release to infer
+	unstable bool
+}
+
+// policies is the lifecycle of the pre-GA API versions under the Kubernetes deprecation policy: a beta introduced in
+// v1.19 or later is deprecated 3 releases after its introduction and removed 3 releases later, the older ones had no
+// such deadline. An alpha can be removed in any release.
+var policies = map[string]lifecycle{
+	"beta":  {since: semver.Must(semver.NewVersion("v1.19.0")), deprecatedAfter: 3, removedAfter: 3},
+	"alpha": {unstable: true},
+}
+
+// preGAVersion matches the pre-GA versions of an API group such as v1beta1 or v2alpha1.
+var preGAVersion = regexp.MustCompile(`^v\d+(alpha|beta)\d*$`)
+
+// WithInferredLifecycle returns a copy of the dataset which infers the lifecycle of the alpha and beta Kubernetes API
+// versions whose deprecation or removal is not in the dataset, from their introduced version and the Kubernetes
+// deprecation policy. Only the betas introduced in v1.19 or later get a deprecated and removed version. The introduced
+// version is the one of their rule, otherwise the earliest one of the Kubernetes rules of the same group and version.
+// The inferred results are returned with Inferred set to true, and the alpha ones, which can be removed in any
+// release, with Unstable set to true rather than a deprecated or removed version.
+func (d *Dataset) WithInferredLifecycle() *Dataset {
+	inferring := *d
+	inferring.infer = true
+	inferring.introduced = d.introducedRules()
+	return &inferring
+}
+
+// inferRule returns the rule of the API version with the versions inferred from the deprecation policy,
+// nil when there's nothing to infer.
+func (d *Dataset) inferRule(r *rule, component, kind, apiVersion string) *rule {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return nil
+	}
+	match := preGAVersion.FindStringSubmatch(gv.Version)
+	if match == nil {
+		return nil
+	}
+	policy, ok := policies[match[1]]
+	if !ok {
+		return nil
+	}
+	if r == nil {
+		if component != "" && component != KubernetesComponent {
+			return nil
+		}
+		introduced := d.introduced[gv]
+		if introduced == nil {
+			return nil
+		}
+		r = &rule{
+			Version:             &Version{APIVersion: apiVersion, Kind: kind, IntroducedInVersion: introduced.IntroducedInVersion},
+			source:              introduced.source,
+			introducedInVersion: introduced.introducedInVersion,
+		}
+	} else if componentOf(r.Component) != KubernetesComponent {
+		return nil
+	}
+
+	inferred := *r
+	if policy.unstable {
+		if r.deprecatedInVersion != nil || r.removedInVersion != nil {
+			return nil
+		}
+		inferred.inferred, inferred.unstable = true, true
+		return &inferred
+	}
+	if policy.since != nil && (inferred.introducedInVersion == nil || inferred.introducedInVersion.LessThan(policy.since)) {
+		return nil
+	}
+	if inferred.deprecatedInVersion == nil && inferred.introducedInVersion != nil {
+		inferred.deprecatedInVersion = inferredRelease(inferred.introducedInVersion, policy.deprecatedAfter)
+	}
+	if inferred.removedInVersion == nil && inferred.deprecatedInVersion != nil {
+		inferred.removedInVersion = inferredRelease(inferred.deprecatedInVersion, policy.removedAfter)
+	}
+	if inferred.deprecatedInVersion == r.deprecatedInVersion && inferred.removedInVersion == r.removedInVersion {
+		return nil
+	}
+	inferred.inferred = true
+	return &inferred
+}
+
+// introducedRules indexes the Kubernetes rule introduced first of every group and version, the group versions
+// without any introduced version are not indexed.
+func (d *Dataset) introducedRules() map[schema.GroupVersion]*rule {
+	first := make(map[schema.GroupVersion]*rule)
+	for _, v := range d.versions.DeprecatedVersions {
+		key := keyOf(v)
+		r := d.index[key]
+		if key.component != KubernetesComponent || r.introducedInVersion == nil {
+			continue
+		}
+		gv := key.gvk.GroupVersion()
+		if existing, ok := first[gv]; !ok || r.introducedInVersion.LessThan(existing.introducedInVersion) {
+			first[gv] = r
+		}
+	}
+	return first
+}
+
+// inferredRelease returns the first release of the minor release after incrementing the minor of the version by
+// specific value, such as v1.22.0 for v1.19.3 and 3.
+func inferredRelease(version *semver.Version, steps int) *semver.Version {
+	segments := version.Segments()
+	v, _ := semver.NewVersion(fmt.Sprintf("v%d.%d.0", segments[0], segments[1]+steps))
+	return v
+}
@@ -0,0 +1,87 @@
+package deprecation
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDatasetInferLifecycle(t *testing.T) {
+	d, err := NewDataset(&Versions{DeprecatedVersions: []*Version{
+		{APIVersion: "flowcontrol.apiserver.k8s.io/v1beta2", Kind: "FlowSchema", IntroducedInVersion: "v1.23.0"},
+		{APIVersion: "flowcontrol.apiserver.k8s.io/v1beta2", Kind: "PriorityLevelConfiguration", IntroducedInVersion: "v1.24.0"},
+		{APIVersion: "resource.k8s.io/v1alpha2", Kind: "ResourceClaim", IntroducedInVersion: "v1.27.0"},
+		{APIVersion: "flowcontrol.apiserver.k8s.io/v1beta3", Kind: "FlowSchema", IntroducedInVersion: "v1.26.0", DeprecatedInVersion: "v1.29.0"},
+		{APIVersion: "batch/v1beta1", Kind: "CronJob", IntroducedInVersion: "v1.8.0", DeprecatedInVersion: "v1.21.0"},
+		{APIVersion: "policy/v1beta1", Kind: "PodSecurityPolicy", DeprecatedInVersion: "v1.21.0", RemovedInVersion: "v1.25.0"},
+		{APIVersion: "autoscaling/v2", Kind: "HorizontalPodAutoscaler", IntroducedInVersion: "v1.23.0"},
+		{APIVersion: "cert-manager.io/v1alpha2", Kind: "Certificate", IntroducedInVersion: "v0.11.0", Component: "cert-manager"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inferring := d.WithInferredLifecycle()
+	versions := ComponentVersions{KubernetesComponent: "v1.26.0", "cert-manager": "v1.5.0"}
+
+	cases := []struct {
+		kind       string
+		apiVersion string
+		known      bool
+		inferred   bool
+		deprecated string
+		removed    string
+	}{
+		// a beta is deprecated 3 releases after its introduction and removed 3 releases later
+		{"FlowSchema", "flowcontrol.apiserver.k8s.io/v1beta2", true, true, "v1.26.0", "v1.29.0"},
+		{"PriorityLevelConfiguration", "flowcontrol.apiserver.k8s.io/v1beta2", true, true, "v1.27.0", "v1.30.0"},
+		// a kind which is not in the dataset is introduced with the first kind of its group and version
+		{"FlowSchemaList", "flowcontrol.apiserver.k8s.io/v1beta2", true, true, "v1.26.0", "v1.29.0"},
+		// an alpha can be removed in any release without deprecation, so it's inferred unstable without any release
+		{"ResourceClaim", "resource.k8s.io/v1alpha2", true, true, NotAvailable, NotAvailable},
+		{"ResourceClaimTemplate", "resource.k8s.io/v1alpha2", true, true, NotAvailable, NotAvailable},
+		// only the missing removal is inferred
+		{"FlowSchema", "flowcontrol.apiserver.k8s.io/v1beta3", true, true, "v1.29.0", "v1.32.0"},
+		// the betas introduced before v1.19 don't follow the policy, such as CronJob removed in v1.25
+		{"CronJob", "batch/v1beta1", true, false, "v1.21.0", NotAvailable},
+		// the curated rules are kept
+		{"PodSecurityPolicy", "policy/v1beta1", true, false, "v1.21.0", "v1.25.0"},
+		// GA API versions are not inferred
+		{"HorizontalPodAutoscaler", "autoscaling/v2", true, false, NotAvailable, NotAvailable},
+		// the other components don't follow the Kubernetes deprecation policy
+		{"Certificate", "cert-manager.io/v1alpha2", true, false, NotAvailable, NotAvailable},
+		// without any introduced version there is nothing to infer from
+		{"Widget", "example.k8s.io/v1beta1", false, false, NotAvailable, NotAvailable},
+	}
+
+	for _, c := range cases {
+		got, err := inferring.CheckComponent("", c.kind, c.apiVersion, versions)
+		if err != nil {
+			t.Fatalf("Unexpected error checking %v %v: %v", c.apiVersion, c.kind, err)
+		}
+		if got.Known != c.known || got.Inferred != c.inferred {
+			t.Fatalf("Expected %v %v to be known: %v and inferred: %v, got: %+v", c.apiVersion, c.kind, c.known, c.inferred, got)
+		}
+		if unstable := c.inferred && strings.Contains(c.apiVersion, "alpha"); got.Unstable != unstable {
+			t.Fatalf("Expected %v %v to be unstable: %v, got: %+v", c.apiVersion, c.kind, unstable, got)
+		}
+		if FormatVersion(got.DeprecatedInVersion) != c.deprecated || FormatVersion(got.RemovedInVersion) != c.removed {
+			t.Fatalf("Expected %v %v to be deprecated in %v and removed in %v, got: %v and %v", c.apiVersion, c.kind,
+				c.deprecated, c.removed, FormatVersion(got.DeprecatedInVersion), FormatVersion(got.RemovedInVersion))
+		}
+	}
+
+	got, err := inferring.Check("FlowSchema", "flowcontrol.apiserver.k8s.io/v1beta2", "v1.29.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Deprecated || !got.Removed {
+		t.Fatalf("Expected the inferred removal to be checked against the Kubernetes version, got: %+v", got)
+	}
+
+	got, err = d.CheckComponent("", "FlowSchema", "flowcontrol.apiserver.k8s.io/v1beta2", versions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Inferred || got.DeprecatedInVersion != nil {
+		t.Fatalf("Expected the dataset not to infer the lifecycle unless enabled, got: %+v", got)
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deprecation
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// kubentAPIsPattern matches the start of the deprecated APIs object of a kube-no-trouble rule
+	kubentAPIsPattern = regexp.MustCompile(`deprecated_apis\s*:?=\s*\{`)
+	// kubentRuleSetPattern matches the release removing the APIs of a kube-no-trouble rule, such as
+	// "RuleSet": "Deprecated APIs removed in 1.16"
+	kubentRuleSetPattern = regexp.MustCompile(`"RuleSet"\s*:\s*"[^"]*removed in (\d+\.\d+(?:\.\d+)?)"`)
+	// kubentPackagePattern matches the package of a kube-no-trouble rule, such as "package deprecated116"
+	kubentPackagePattern = regexp.MustCompile(`(?m)^package deprecated(\d)(\d+)\s*$`)
+	// trailingCommaPattern matches the trailing commas allowed by Rego but not by JSON
+	trailingCommaPattern = regexp.MustCompile(`,(\s*[}\]])`)
+)
+
+// kubentAPI is a deprecated kind of a kube-no-trouble rule.
+type kubentAPI struct {
+	Old   []string `json:"old"`
+	New   string   `json:"new"`
+	Since string   `json:"since"`
+}
+
+// ParseKubentRego parses a kube-no-trouble (https://github.com/doitintl/kube-no-trouble) Rego rule, such as
+// deprecated-1-16.rego, and converts it to deprecated API versions. The release removing the API versions is read
+// from the RuleSet or the package name, it's left empty for the APIs removed in a future release.
+// The API versions are sorted by kind, in the order of the rule for the same kind.
+func ParseKubentRego(content []byte) (*Versions, error) {
+	text := stripRegoComments(string(content))
+	loc := kubentAPIsPattern.FindStringIndex(text)
+	if loc == nil {
+		return nil, fmt.Errorf("no deprecated_apis object in the rule")
+	}
+	object, err := regoObject(text[loc[1]-1:])
+	if err != nil {
+		return nil, err
+	}
+	var apis map[string]kubentAPI
+	if err := json.Unmarshal([]byte(trailingCommaPattern.ReplaceAllString(object, "$1")), &apis); err != nil {
+		return nil, fmt.Errorf("invalid deprecated_apis object: %w", err)
+	}
+
+	removedIn := ""
+	if m := kubentRuleSetPattern.FindStringSubmatch(text); m != nil {
+		removedIn = releaseVersion(m[1])
+	} else if m := kubentPackagePattern.FindStringSubmatch(text); m != nil {
+		removedIn = releaseVersion(m[1] + "." + m[2])
+	}
+
+	kinds := make([]string, 0, len(apis))
+	for kind := range apis {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	versions := new(Versions)
+	for _, kind := range kinds {
+		api := apis[kind]
+		for _, old := range api.Old {
+			versions.DeprecatedVersions = append(versions.DeprecatedVersions, &Version{
+				APIVersion:          old,
+				Kind:                kind,
+				DeprecatedInVersion: releaseVersion(api.Since),
+				RemovedInVersion:    removedIn,
+				ReplacementAPI:      api.New,
+			})
+		}
+	}
+	return versions, nil
+}
+
+// stripRegoComments removes the comments, the # which are not in a string start a comment.
+func stripRegoComments(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		inString := false
+		for j := 0; j < len(line); j++ {
+			if line[j] == '"' && (j == 0 || line[j-1] != '\\') {
+				inString = !inString
+			} else if line[j] == '#' && !inString {
+				lines[i] = line[:j]
+				break
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// regoObject returns the object literal at the start of the text, up to its closing brace.
+func regoObject(text string) (string, error) {
+	depth := 0
+	inString := false
+	for i, c := range text {
+		switch {
+		case c == '"' && (i == 0 || text[i-1] != '\\'):
+			inString = !inString
+		case inString:
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return text[:i+1], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("unterminated deprecated_apis object")
+}
+
+// releaseVersion formats a release such as 1.16 like the versions file, v1.16.0. An empty release is kept empty.
+func releaseVersion(release string) string {
+	if release == "" {
+		return ""
+	}
+	release = strings.TrimPrefix(release, "v")
+	if strings.Count(release, ".") == 1 {
+		release += ".0"
+	}
+	return "v" + release
+}
@@ -0,0 +1,51 @@
+package deprecation
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestParseKubentRego(t *testing.T) {
+	content, err := ioutil.ReadFile("testdata/deprecated-1-16.rego")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ParseKubentRego(content)
+	if err != nil {
+		t.Fatalf("Unexpected error parsing the rule: %v", err)
+	}
+	expected := []*Version{
+		{APIVersion: "apps/v1beta1", Kind: "Deployment", DeprecatedInVersion: "v1.9.0", RemovedInVersion: "v1.16.0", ReplacementAPI: "apps/v1"},
+		{APIVersion: "apps/v1beta2", Kind: "Deployment", DeprecatedInVersion: "v1.9.0", RemovedInVersion: "v1.16.0", ReplacementAPI: "apps/v1"},
+		{APIVersion: "extensions/v1beta1", Kind: "Deployment", DeprecatedInVersion: "v1.9.0", RemovedInVersion: "v1.16.0", ReplacementAPI: "apps/v1"},
+		{APIVersion: "extensions/v1beta1", Kind: "NetworkPolicy", DeprecatedInVersion: "v1.8.0", RemovedInVersion: "v1.16.0", ReplacementAPI: "networking.k8s.io/v1"},
+	}
+	if !reflect.DeepEqual(got.DeprecatedVersions, expected) {
+		for _, v := range got.DeprecatedVersions {
+			t.Logf("%+v", v)
+		}
+		t.Fatalf("Unexpected deprecated API versions")
+	}
+}
+
+func TestParseKubentRegoFuture(t *testing.T) {
+	rule := `package deprecatedfuture
+
+deprecated_api(kind, api_version) = api {
+	deprecated_apis = {"PodSecurityPolicy": {"old": ["policy/v1beta1"], "new": "", "since": "1.21"}}
+}`
+	got, err := ParseKubentRego([]byte(rule))
+	if err != nil {
+		t.Fatalf("Unexpected error parsing the rule: %v", err)
+	}
+	if len(got.DeprecatedVersions) != 1 || got.DeprecatedVersions[0].RemovedInVersion != "" || got.DeprecatedVersions[0].DeprecatedInVersion != "v1.21.0" {
+		t.Fatalf("Unexpected deprecated API versions: %+v", got.DeprecatedVersions[0])
+	}
+
+	for _, invalid := range []string{"package deprecated116", "deprecated_apis = {\"Deployment\": {", "deprecated_apis = {\"Deployment\": []}"} {
+		if _, err := ParseKubentRego([]byte(invalid)); err == nil {
+			t.Fatalf("Expected an error for %q", invalid)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deprecation
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Source is a named set of deprecated API versions such as a versions file.
+type Source struct {
+	// Name identifies the source in the results and the conflicts
+	Name string
+	// Versions are the deprecated API versions of the source, nil is the same as empty
+	Versions *Versions
+}
+
+// Conflict describes an API version of specific kind, or a field or annotation of a kind, which is defined
+// differently more than once.
+type Conflict struct {
+	// Component is the name of the component serving the API version
+	Component string
+	// APIVersion is the name of the API version used by specific kind.
+	APIVersion string
+	// Kind is the Object type such as "Deployment" or "Ingress"
+	Kind string
+	// Field is the field or annotation of the kind, empty for an API version
+	Field string
+	// Used is the name of the source whose definition is used
+	Used string
+	// Ignored is the name of the source whose definition is ignored
+	Ignored string
+}
+
+func (c Conflict) String() string {
+	if c.Field != "" {
+		return fmt.Sprintf("%s %s %s %s is defined by %q and %q, using %q", c.Component, c.APIVersion, c.Kind, c.Field, c.Used, c.Ignored, c.Used)
+	}
+	return fmt.Sprintf("%s %s %s is defined by %q and %q, using %q", c.Component, c.APIVersion, c.Kind, c.Used, c.Ignored, c.Used)
+}
+
+// Merge merges the sources into one Dataset. The sources are ordered by precedence:
+//
+//   - an API version of specific kind in a source overrides the same component, API version and kind
+//     of all the sources before it
+//   - when a source lists the same component, API version and kind more than once, its first entry is used
+//
+// The deprecated fields and annotations are merged the same way, by component, API version, kind and field or annotation.
+// Every overridden or ignored entry which is not identical to the used one is returned as a Conflict.
+// An error is returned when any of the sources has an invalid version.
+func Merge(sources ...Source) (*Dataset, []Conflict, error) {
+	d := newDataset()
+	var conflicts []Conflict
+
+	for precedence, source := range sources {
+		if source.Versions == nil {
+			continue
+		}
+		seen := make(map[ruleKey]bool, len(source.Versions.DeprecatedVersions))
+		for _, dep := range source.Versions.DeprecatedVersions {
+			key := keyOf(dep)
+			r, err := newRule(dep, source.Name)
+			if err != nil {
+				if source.Name != "" {
+					return nil, nil, fmt.Errorf("%s: %w", source.Name, err)
+				}
+				return nil, nil, err
+			}
+
+			existing, ok := d.index[key]
+			if !ok {
+				seen[key] = true
+				d.set(key, r)
+				continue
+			}
+
+			identical := reflect.DeepEqual(existing.Version, dep)
+			conflict := Conflict{Component: key.component, APIVersion: dep.APIVersion, Kind: dep.Kind, Used: source.Name, Ignored: existing.source}
+			if seen[key] {
+				if !identical {
+					conflict.Ignored = source.Name
+					conflicts = append(conflicts, conflict)
+				}
+				continue
+			}
+			if !identical {
+				conflicts = append(conflicts, conflict)
+			}
+			seen[key] = true
+			d.set(key, r)
+		}
+
+		seenFields := make(map[fieldKey]bool, len(source.Versions.DeprecatedFields))
+		for _, dep := range source.Versions.DeprecatedFields {
+			key := fieldKeyOf(dep)
+			r, err := newFieldRule(dep, source.Name)
+			if err != nil {
+				if source.Name != "" {
+					return nil, nil, fmt.Errorf("%s: %w", source.Name, err)
+				}
+				return nil, nil, err
+			}
+			r.precedence = precedence
+
+			i, ok := d.fieldPosition[key]
+			if !ok {
+				seenFields[key] = true
+				d.setField(key, r)
+				continue
+			}
+
+			existing := d.fields[i]
+			identical := reflect.DeepEqual(existing.FieldVersion, dep)
+			conflict := Conflict{Component: key.component, APIVersion: dep.APIVersion, Kind: dep.Kind, Field: dep.Name(), Used: source.Name, Ignored: existing.source}
+			if seenFields[key] {
+				if !identical {
+					conflict.Ignored = source.Name
+					conflicts = append(conflicts, conflict)
+				}
+				continue
+			}
+			if !identical {
+				conflicts = append(conflicts, conflict)
+			}
+			seenFields[key] = true
+			d.setField(key, r)
+		}
+	}
+	d.revision = revisionOf(d.versions)
+	return d, conflicts, nil
+}
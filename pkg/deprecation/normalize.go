@@ -0,0 +1,59 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deprecation
+
+import (
+	"regexp"
+
+	semver "github.com/hashicorp/go-version"
+)
+
+// distribution matches the Kubernetes versions reported by a distribution, its first submatch is the upstream version.
+type distribution struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// distributions lists the distributions adding a suffix to the Kubernetes version. The suffixes such as -eks-8cb36c9
+// or -gke.1203001 are parsed as pre-releases, which are older than the upstream release they are built from.
+// AKS reports the upstream version as is.
+var distributions = []distribution{
+	{name: "EKS", pattern: regexp.MustCompile(`^(v?\d+\.\d+\.\d+)-eks-[0-9a-f]+$`)},
+	{name: "GKE", pattern: regexp.MustCompile(`^(v?\d+\.\d+\.\d+)-gke\.\d+$`)},
+	{name: "k3s", pattern: regexp.MustCompile(`^(v?\d+\.\d+\.\d+)\+k3s\d+$`)},
+	{name: "RKE2", pattern: regexp.MustCompile(`^(v?\d+\.\d+\.\d+)\+rke2r\d+$`)},
+	{name: "OpenShift", pattern: regexp.MustCompile(`^(v?\d+\.\d+\.\d+)\+[0-9a-f]{7,40}$`)},
+}
+
+// distributionOf returns the distribution which reported the version, nil for the upstream versions.
+func distributionOf(version string) *distribution {
+	for i := range distributions {
+		if distributions[i].pattern.MatchString(version) {
+			return &distributions[i]
+		}
+	}
+	return nil
+}
+
+// NormalizeVersion parses an installed version, the suffix added by a Kubernetes distribution is dropped so
+// v1.27.8-eks-8cb36c9 is parsed as v1.27.8. The other versions, including the upstream pre-releases, are parsed as is.
+func NormalizeVersion(version string) (*semver.Version, error) {
+	if d := distributionOf(version); d != nil {
+		version = d.pattern.FindStringSubmatch(version)[1]
+	}
+	return semver.NewVersion(version)
+}
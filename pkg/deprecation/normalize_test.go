@@ -0,0 +1,52 @@
+package deprecation
+
+import "testing"
+
+func TestNormalizeVersion(t *testing.T) {
+	cases := []struct {
+		version      string
+		distribution string
+		expected     string
+	}{
+		{"v1.27.8-eks-8cb36c9", "EKS", "v1.27.8"},
+		{"v1.21.14-eks-fb459a0", "EKS", "v1.21.14"},
+		{"v1.28.3-gke.1203001", "GKE", "v1.28.3"},
+		{"v1.16.15-gke.6000", "GKE", "v1.16.15"},
+		{"v1.26.5+k3s1", "k3s", "v1.26.5"},
+		{"v1.26.5+rke2r1", "RKE2", "v1.26.5"},
+		{"v1.27.6+f67aeb3", "OpenShift", "v1.27.6"},
+		{"v1.11.0+d4cacc0", "OpenShift", "v1.11.0"},
+		{"1.27.8-eks-8cb36c9", "EKS", "1.27.8"},
+		{"v1.27.7", "", "v1.27.7"},
+		{"v1.25.0-alpha.3", "", "v1.25.0-alpha.3"},
+		{"v1.26.0-rc.0", "", "v1.26.0-rc.0"},
+		{"v1.8.0-beta.0", "", "v1.8.0-beta.0"},
+		{"v1.27.8-custom.1", "", "v1.27.8-custom.1"},
+		{"v1.2.0-x.Y.0+metadata", "", "v1.2.0-x.Y.0+metadata"},
+	}
+
+	for _, c := range cases {
+		name := ""
+		if d := distributionOf(c.version); d != nil {
+			name = d.name
+		}
+		if name != c.distribution {
+			t.Fatalf("Expected version %v to be reported by %q, got: %q", c.version, c.distribution, name)
+		}
+		got, err := NormalizeVersion(c.version)
+		if err != nil {
+			t.Fatalf("Unexpected error normalizing version %v: %v", c.version, err)
+		}
+		if got.Original() != c.expected {
+			t.Fatalf("Expected version %v to be normalized to %v, got: %v", c.version, c.expected, got.Original())
+		}
+	}
+}
+
+func TestNormalizeVersionInvalid(t *testing.T) {
+	for _, version := range []string{"", "latest", "eks-8cb36c9"} {
+		if _, err := NormalizeVersion(version); err == nil {
+			t.Fatalf("Expected an error normalizing version %q", version)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deprecation
+
+import (
+	"sigs.k8s.io/yaml"
+)
+
+// PlutoVersions maps the versions file of Pluto (https://github.com/FairwindsOps/pluto).
+type PlutoVersions struct {
+	// TargetVersions are the versions of the components the API versions are checked against
+	TargetVersions map[string]string `json:"target-versions,omitempty" yaml:"target-versions,omitempty"`
+	// DeprecatedVersions are a list of deprecated API versions.
+	DeprecatedVersions []*PlutoVersion `json:"deprecated-versions" yaml:"deprecated-versions"`
+}
+
+// PlutoVersion is a deprecated API version in the Pluto format.
+type PlutoVersion struct {
+	Version        string `json:"version" yaml:"version"`
+	Kind           string `json:"kind" yaml:"kind"`
+	DeprecatedIn   string `json:"deprecated-in" yaml:"deprecated-in"`
+	RemovedIn      string `json:"removed-in" yaml:"removed-in"`
+	ReplacementAPI string `json:"replacement-api" yaml:"replacement-api"`
+	Component      string `json:"component" yaml:"component"`
+}
+
+// ParsePluto parses a Pluto versions file and converts it to deprecated API versions,
+// its target versions are returned as the installed versions of the components.
+func ParsePluto(content []byte) (*Versions, ComponentVersions, error) {
+	var pluto PlutoVersions
+	if err := yaml.Unmarshal(content, &pluto); err != nil {
+		return nil, nil, err
+	}
+	versions := &Versions{DeprecatedVersions: make([]*Version, 0, len(pluto.DeprecatedVersions))}
+	for _, p := range pluto.DeprecatedVersions {
+		versions.DeprecatedVersions = append(versions.DeprecatedVersions, &Version{
+			APIVersion:          p.Version,
+			Kind:                p.Kind,
+			DeprecatedInVersion: p.DeprecatedIn,
+			RemovedInVersion:    p.RemovedIn,
+			ReplacementAPI:      p.ReplacementAPI,
+			Component:           p.Component,
+		})
+	}
+	return versions, ComponentVersions(pluto.TargetVersions), nil
+}
+
+// MarshalPluto converts the deprecated API versions to a Pluto versions file with the target versions of the components.
+// Pluto requires a component, so the API versions without component are exported as Kubernetes ones.
+// Pluto only matches kinds, so the rules with a kind pattern such as "*" are not exported. Pluto doesn't know when
+// the API versions are introduced, so the rules which are neither deprecated nor removed are not exported either.
+func MarshalPluto(v *Versions, targetVersions ComponentVersions) ([]byte, error) {
+	pluto := PlutoVersions{TargetVersions: targetVersions, DeprecatedVersions: []*PlutoVersion{}}
+	for _, dep := range v.DeprecatedVersions {
+		if isKindPattern(dep.Kind) || (dep.DeprecatedInVersion == "" && dep.RemovedInVersion == "") {
+			continue
+		}
+		pluto.DeprecatedVersions = append(pluto.DeprecatedVersions, &PlutoVersion{
+			Version:        dep.APIVersion,
+			Kind:           dep.Kind,
+			DeprecatedIn:   dep.DeprecatedInVersion,
+			RemovedIn:      dep.RemovedInVersion,
+			ReplacementAPI: dep.ReplacementAPI,
+			Component:      componentOf(dep.Component),
+		})
+	}
+	return marshalYAML(pluto)
+}
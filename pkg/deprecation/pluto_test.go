@@ -0,0 +1,69 @@
+package deprecation
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestParsePluto(t *testing.T) {
+	content, err := ioutil.ReadFile("testdata/pluto-versions.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, targets, err := ParsePluto(content)
+	if err != nil {
+		t.Fatalf("Unexpected error parsing the Pluto versions: %v", err)
+	}
+	expected := []*Version{
+		{APIVersion: "extensions/v1beta1", Kind: "Deployment", DeprecatedInVersion: "v1.9.0", RemovedInVersion: "v1.16.0", ReplacementAPI: "apps/v1", Component: "k8s"},
+		{APIVersion: "cert-manager.io/v1alpha2", Kind: "Certificate", DeprecatedInVersion: "v1.4.0", RemovedInVersion: "v1.6.0", ReplacementAPI: "cert-manager.io/v1", Component: "cert-manager"},
+	}
+	if !reflect.DeepEqual(got.DeprecatedVersions, expected) {
+		t.Fatalf("Unexpected deprecated API versions: %+v", got.DeprecatedVersions)
+	}
+	if !reflect.DeepEqual(targets, ComponentVersions{"k8s": "v1.25.0", "cert-manager": "v1.8.0"}) {
+		t.Fatalf("Unexpected target versions: %v", targets)
+	}
+}
+
+func TestMarshalPluto(t *testing.T) {
+	v, err := getDeprecatedVersions(versionsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := MarshalPluto(v, ComponentVersions{"k8s": "v1.22.0"})
+	if err != nil {
+		t.Fatalf("Unexpected error exporting the versions: %v", err)
+	}
+	got, targets, err := ParsePluto(content)
+	if err != nil {
+		t.Fatalf("Unexpected error parsing the exported versions: %v", err)
+	}
+	if targets["k8s"] != "v1.22.0" {
+		t.Fatalf("Expected the target versions to be exported, got: %v", targets)
+	}
+	// the rules with a kind pattern or only introduced are not exported, and Pluto doesn't keep the introduced versions
+	// nor the migration guidance
+	for _, c := range Diff(v, got) {
+		switch c.Type {
+		case Removed:
+			if isKindPattern(c.Old.Kind) || (c.Old.DeprecatedInVersion == "" && c.Old.RemovedInVersion == "") {
+				continue
+			}
+		case Modified:
+			exported := *c.Old
+			exported.IntroducedInVersion = ""
+			exported.Severity, exported.MigrationNote, exported.MigrationGuide, exported.FieldChanges = "", "", "", nil
+			if reflect.DeepEqual(withComponent(&exported), withComponent(c.New)) {
+				continue
+			}
+		}
+		t.Fatalf("Expected the exported versions to be imported back to the same versions, got: %+v", c.Version())
+	}
+	for _, dep := range got.DeprecatedVersions {
+		if dep.Component == "" {
+			t.Fatalf("Expected every exported API version to have a component, got: %+v", dep)
+		}
+	}
+}
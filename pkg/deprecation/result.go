@@ -0,0 +1,131 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deprecation
+
+import (
+	"time"
+
+	semver "github.com/hashicorp/go-version"
+)
+
+// NotAvailable is the value reported for versions and APIs that are not set.
+const NotAvailable = "n/a"
+
+// Result describes the deprecation status of an API version of specific kind
+// evaluated against a Kubernetes version.
+type Result struct {
+	// Kind is the Object type such as "Deployment" or "Ingress"
+	Kind string
+	// APIVersion is the name of the API version used by specific kind.
+	APIVersion string
+	// Component is the name of the component serving the API version
+	Component string
+	// ComponentVersion is the installed version of the component the status is checked against,
+	// nil if it's unknown
+	ComponentVersion *semver.Version
+	// Known is false when the API version of specific kind is not in the dataset and its lifecycle is not inferred,
+	// in that case the rest of the fields keep their zero values.
+	Known bool
+	// Inferred is true when the deprecated or removed version, or the Unstable flag, is inferred from the Kubernetes
+	// deprecation policy rather than curated in the dataset, see Dataset.WithInferredLifecycle
+	Inferred bool
+	// Unstable is true when the API version is an alpha Kubernetes API version whose lifecycle is inferred: it's
+	// neither deprecated nor removed in a known release, but it may be removed in any release without deprecation
+	Unstable bool
+	// Source is the name of the dataset source the matching rule is loaded from
+	Source string
+	// Whether the API version is not served yet because it's introduced in a later version
+	NotYetAvailable bool
+	// Whether the API version is deprecated or not
+	Deprecated bool
+	// Whether the API version is removed or not
+	Removed bool
+	// Whether the API version will be removed in the next release or not
+	RemovedInNextRelease bool
+	// Whether the API version will be removed in the next two releases or not
+	RemovedInNextTwoReleases bool
+	// ReleasesUntilRemoval is the number of minor releases until the API version is removed, 0 once it's removed.
+	// It's nil when the API version is not removed in a known release, or not in the same major release.
+	ReleasesUntilRemoval *int
+	// RemovalDate is the date the cluster is expected to run the release which removes the API version,
+	// nil when it's unknown. It's only set by ScheduleRemoval.
+	RemovalDate *time.Time
+	// DaysUntilRemoval is the number of days until the RemovalDate, 0 once it's reached
+	DaysUntilRemoval *int
+	// Kubernetes version in which the API version is introduced in, nil if not set
+	IntroducedInVersion *semver.Version
+	// Kubernetes version in which the API version is deprecated in, nil if not set
+	DeprecatedInVersion *semver.Version
+	// Kubernetes version in which the API version is removed in, nil if not set
+	RemovedInVersion *semver.Version
+	// Replacement is the new supported API, nil if not set
+	Replacement *Replacement
+	// Severity is how urgent the migration is: info, warning or critical, empty if not set
+	Severity string
+	// MigrationNote is a short note on how to migrate to the replacement, empty if not set
+	MigrationNote string
+	// MigrationGuide is the URL of the upstream migration guide, empty if not set
+	MigrationGuide string
+	// FieldChanges are the notable field changes between the API version and the replacement
+	FieldChanges []string
+}
+
+// Replacement references the API that should be used instead of a deprecated or removed API version.
+type Replacement struct {
+	// APIVersion is the name of the new supported API version
+	APIVersion string
+	// Kind is the Object type served by the new API version
+	Kind string
+}
+
+// ReplacementAPI returns the replacement API version or "n/a" if there is none.
+func (r *Result) ReplacementAPI() string {
+	if r.Replacement == nil {
+		return NotAvailable
+	}
+	return r.Replacement.APIVersion
+}
+
+// RemovedWithin checks if the API version is removed in the number of releases, or already removed.
+func (r *Result) RemovedWithin(releases int) bool {
+	return r.ReleasesUntilRemoval != nil && *r.ReleasesUntilRemoval <= releases
+}
+
+// FormatVersion returns the version as written in the dataset or "n/a" if it is nil.
+func FormatVersion(v *semver.Version) string {
+	if v == nil {
+		return NotAvailable
+	}
+	return v.Original()
+}
+
+// ScheduleRemoval sets the removal date of a Kubernetes API version from the release calendar, and the days from now
+// until then. The API versions of the other components are not in the calendar.
+func (r *Result) ScheduleRemoval(c *Calendar, now time.Time) {
+	if r.Component != KubernetesComponent || r.ComponentVersion == nil {
+		return
+	}
+	date, ok := c.RemovalDate(r.RemovedInVersion)
+	if !ok {
+		return
+	}
+	days := DaysUntil(date, now)
+	if r.Removed {
+		days = 0
+	}
+	r.RemovalDate, r.DaysUntilRemoval = &date, &days
+}
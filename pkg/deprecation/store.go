@@ -0,0 +1,107 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deprecation
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Store holds the current Dataset merged from several named sources and allows
+// swapping it atomically while it's being read.
+type Store struct {
+	dataset atomic.Value
+
+	mu        sync.Mutex
+	sources   []string
+	versions  map[string]*Versions
+	conflicts []Conflict
+}
+
+// NewStore creates a new empty Store for the provided sources ordered by precedence,
+// the API versions of a source override the API versions of the sources before it.
+func NewStore(sources ...string) *Store {
+	s := &Store{
+		sources:  sources,
+		versions: make(map[string]*Versions, len(sources)),
+	}
+	d, _, _ := Merge()
+	s.dataset.Store(d)
+	return s
+}
+
+// Get returns the current Dataset.
+func (s *Store) Get() *Dataset {
+	return s.dataset.Load().(*Dataset)
+}
+
+// Update replaces the API versions of the source and swaps the current Dataset with the merged one.
+// The current Dataset is kept when the merged one is not valid.
+func (s *Store) Update(source string, v *Versions) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.hasSource(source) {
+		return fmt.Errorf("unknown deprecation source %q", source)
+	}
+	previous, ok := s.versions[source]
+	s.versions[source] = v
+	d, conflicts, err := s.merge()
+	if err != nil {
+		if ok {
+			s.versions[source] = previous
+		} else {
+			delete(s.versions, source)
+		}
+		return err
+	}
+	s.conflicts = conflicts
+	s.dataset.Store(d)
+	return nil
+}
+
+// Sources returns the names of the sources ordered by precedence, the last one has the highest precedence.
+func (s *Store) Sources() []string {
+	return append([]string(nil), s.sources...)
+}
+
+// Conflicts returns the conflicts between the sources of the current Dataset.
+func (s *Store) Conflicts() []Conflict {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Conflict(nil), s.conflicts...)
+}
+
+// merge creates a Dataset from the API versions of all the sources.
+func (s *Store) merge() (*Dataset, []Conflict, error) {
+	sources := make([]Source, 0, len(s.sources))
+	for _, name := range s.sources {
+		sources = append(sources, Source{Name: name, Versions: s.versions[name]})
+	}
+	return Merge(sources...)
+}
+
+// hasSource checks if the source is one of the sources of the store.
+func (s *Store) hasSource(source string) bool {
+	for _, name := range s.sources {
+		if name == source {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package deprecation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	base := &Versions{DeprecatedVersions: []*Version{
+		{APIVersion: "extensions/v1beta1", Kind: "Ingress", RemovedInVersion: "v1.22.0"},
+		{APIVersion: "apps/v1beta1", Kind: "Deployment", RemovedInVersion: "v1.16.0"},
+	}}
+	override := &Versions{DeprecatedVersions: []*Version{
+		{APIVersion: "extensions/v1beta1", Kind: "Ingress", RemovedInVersion: "v1.23.0"},
+		{APIVersion: "extensions/v1beta1", Kind: "Ingress", RemovedInVersion: "v1.24.0"},
+		{APIVersion: "apps/v1beta1", Kind: "Deployment", RemovedInVersion: "v1.16.0"},
+		{APIVersion: "batch/v1beta1", Kind: "CronJob", RemovedInVersion: "v1.25.0"},
+	}}
+
+	d, conflicts, err := Merge(Source{Name: "base", Versions: base}, Source{Name: "empty"}, Source{Name: "override", Versions: override})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Len() != 3 || len(d.Versions().DeprecatedVersions) != 3 {
+		t.Fatalf("Expected 3 merged API versions, got: %d", d.Len())
+	}
+	if d.Versions().DeprecatedVersions[0].RemovedInVersion != "v1.23.0" {
+		t.Fatalf("Expected the override to replace the base API version, got: %+v", d.Versions().DeprecatedVersions[0])
+	}
+
+	sources := []struct {
+		kind       string
+		apiVersion string
+		source     string
+	}{
+		{"Ingress", "extensions/v1beta1", "override"},
+		{"Deployment", "apps/v1beta1", "override"},
+		{"CronJob", "batch/v1beta1", "override"},
+	}
+	for _, s := range sources {
+		got, _ := d.Check(s.kind, s.apiVersion, "v1.20.0")
+		if got.Source != s.source {
+			t.Fatalf("Expected %s %s to be loaded from %s, got: %s", s.apiVersion, s.kind, s.source, got.Source)
+		}
+	}
+
+	expected := []Conflict{
+		{Component: "k8s", APIVersion: "extensions/v1beta1", Kind: "Ingress", Used: "override", Ignored: "base"},
+		{Component: "k8s", APIVersion: "extensions/v1beta1", Kind: "Ingress", Used: "override", Ignored: "override"},
+	}
+	if !reflect.DeepEqual(conflicts, expected) {
+		t.Fatalf("Conflicts: %v don't match the expected conflicts: %v", conflicts, expected)
+	}
+
+	if _, _, err := Merge(Source{Name: "invalid", Versions: &Versions{DeprecatedVersions: []*Version{{APIVersion: "v1", Kind: "Pod", RemovedInVersion: "never"}}}}); err == nil {
+		t.Fatalf("Expected an error for an invalid source")
+	}
+}
+
+func TestStoreUpdate(t *testing.T) {
+	s := NewStore("file", "rules")
+	if s.Get().Len() != 0 {
+		t.Fatalf("Expected a new store to be empty")
+	}
+
+	file := &Versions{DeprecatedVersions: []*Version{
+		{APIVersion: "extensions/v1beta1", Kind: "Ingress", RemovedInVersion: "v1.22.0"},
+	}}
+	rules := &Versions{DeprecatedVersions: []*Version{
+		{APIVersion: "extensions/v1beta1", Kind: "Ingress", RemovedInVersion: "v1.23.0"},
+	}}
+	// The rules source overrides the file source whatever the update order is.
+	if err := s.Update("rules", rules); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Update("file", file); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := s.Get().Check("Ingress", "extensions/v1beta1", "v1.22.0")
+	if got.Removed {
+		t.Fatalf("Expected the rules source to override the file source")
+	}
+
+	invalid := &Versions{DeprecatedVersions: []*Version{
+		{APIVersion: "extensions/v1beta1", Kind: "Ingress", RemovedInVersion: "soon"},
+	}}
+	current := s.Get()
+	if err := s.Update("rules", invalid); err == nil || s.Get() != current {
+		t.Fatalf("Expected an invalid source to be rejected and the current dataset to be kept")
+	}
+	if err := s.Update("rules", &Versions{}); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = s.Get().Check("Ingress", "extensions/v1beta1", "v1.22.0")
+	if !got.Removed {
+		t.Fatalf("Expected the file source to be used once the rules source is empty")
+	}
+
+	if err := s.Update("unknown", file); err == nil {
+		t.Fatalf("Expected an error for an unknown source")
+	}
+}
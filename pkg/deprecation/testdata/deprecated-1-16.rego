@@ -0,0 +1,44 @@
+package deprecated116
+
+main[return] {
+	resource := input[_]
+	api := deprecated_resource(resource)
+	return := {
+		"Name": get_default(resource.metadata, "name", "<undefined>"),
+		# Namespace is not defined for cluster-scoped resources
+		"Namespace": get_default(resource.metadata, "namespace", "<undefined>"),
+		"Kind": resource.kind,
+		"ApiVersion": resource.apiVersion,
+		"ReplaceWith": api.new,
+		"RuleSet": "Deprecated APIs removed in 1.16",
+		"Since": api.since,
+	}
+}
+
+deprecated_resource(r) = api {
+	api := deprecated_api(r.kind, r.apiVersion)
+}
+
+deprecated_api(kind, api_version) = api {
+	deprecated_apis = {
+		"Deployment": {
+			"old": ["apps/v1beta1", "apps/v1beta2", "extensions/v1beta1"],
+			"new": "apps/v1",
+			"since": "1.9",
+		},
+		# the NetworkPolicy moved to its own group
+		"NetworkPolicy": {
+			"old": ["extensions/v1beta1"],
+			"new": "networking.k8s.io/v1",
+			"since": "1.8",
+		},
+	}
+
+	deprecated_apis[kind].old[_] == api_version
+
+	api := {
+		"old": api_version,
+		"new": deprecated_apis[kind].new,
+		"since": deprecated_apis[kind].since,
+	}
+}
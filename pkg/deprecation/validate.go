@@ -0,0 +1,519 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deprecation
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	semver "github.com/hashicorp/go-version"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// Severity is the severity of a finding.
+type Severity string
+
+const (
+	// SeverityError is a finding which makes the rule wrong or ignored
+	SeverityError Severity = "error"
+	// SeverityWarning is a finding which is most likely a mistake
+	SeverityWarning Severity = "warning"
+)
+
+// The checks of the validator.
+const (
+	CheckSyntax                     = "syntax"
+	CheckSchema                     = "schema"
+	CheckVersion                    = "version"
+	CheckVersionFormat              = "version-format"
+	CheckDuplicate                  = "duplicate"
+	CheckConflict                   = "conflict"
+	CheckRemovedBeforeDeprecated    = "removed-before-deprecated"
+	CheckIntroducedAfterDeprecation = "introduced-after-deprecation"
+	CheckDeprecatedReplacement      = "deprecated-replacement"
+)
+
+// Finding is a problem found in a versions file.
+type Finding struct {
+	// Line is the line of the problem, starting at 1, 0 when it's unknown
+	Line     int      `json:"line"`
+	Severity Severity `json:"severity"`
+	// Check is the name of the check which found the problem
+	Check   string `json:"check"`
+	Message string `json:"message"`
+	// APIVersion, Kind and Component identify the rule with the problem, if any
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	Component  string `json:"component,omitempty"`
+	// Field is the field or annotation of the rule with the problem, if any
+	Field string `json:"field,omitempty"`
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%d: %s: %s (%s)", f.Line, f.Severity, f.Message, f.Check)
+}
+
+var (
+	// canonicalVersionPattern matches the version format of the versions file, such as v1.16.0
+	canonicalVersionPattern = regexp.MustCompile(`^v\d+\.\d+\.\d+$`)
+	// apiVersionFormatPattern matches an API version such as v1 or apps/v1beta1
+	apiVersionFormatPattern = regexp.MustCompile(`^([a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*/)?v\d+((alpha|beta)\d+)?$`)
+	// yamlErrorLinePattern matches the line of a YAML syntax error
+	yamlErrorLinePattern = regexp.MustCompile(`line (\d+)`)
+)
+
+// versionFieldNames are the fields of a deprecated API version in the versions file
+var versionFieldNames = yamlFieldNames(reflect.TypeOf(Version{}))
+
+// fieldVersionFieldNames are the fields of a deprecated field or annotation in the versions file
+var fieldVersionFieldNames = yamlFieldNames(reflect.TypeOf(FieldVersion{}))
+
+// listFieldNames are the fields of the versions file which are lists of strings
+var listFieldNames = map[string]bool{"fieldChanges": true}
+
+// yamlFieldNames returns the YAML names of the fields of the struct type.
+func yamlFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// validatedRule is a rule of the versions file being validated.
+type validatedRule struct {
+	*Version
+	line                            int
+	introduced, deprecated, removed *semver.Version
+	// the lines of the fields
+	introducedInLine, deprecatedInLine, removedInLine, replacementAPILine int
+}
+
+// validatedField is a deprecated field or annotation of the versions file being validated.
+type validatedField struct {
+	*FieldVersion
+	line                            int
+	deprecated, removed             *semver.Version
+	deprecatedInLine, removedInLine int
+}
+
+// Validate checks the content of a versions file and returns its problems sorted by line:
+//
+//   - syntax and schema errors, such as unknown fields, missing fields or an invalid API version
+//   - versions which can't be parsed, and versions which are not in the vMAJOR.MINOR.PATCH format
+//   - the same API version listed more than once, identically or with different fields
+//   - API versions removed before they are deprecated, or introduced after they are deprecated or removed
+//   - replacements which are deprecated themselves
+//   - unknown severities, and migration guides which are not http or https URLs
+//
+// The deprecated fields and annotations are checked the same way.
+func Validate(content []byte) []Finding {
+	var root yamlv3.Node
+	if err := yamlv3.Unmarshal(content, &root); err != nil {
+		return []Finding{{Line: yamlErrorLine(err), Severity: SeverityError, Check: CheckSyntax, Message: err.Error()}}
+	}
+	if len(root.Content) == 0 {
+		return []Finding{{Line: 1, Severity: SeverityError, Check: CheckSchema, Message: "the versions file is empty"}}
+	}
+	doc := root.Content[0]
+	if doc.Kind != yamlv3.MappingNode {
+		return []Finding{{Line: doc.Line, Severity: SeverityError, Check: CheckSchema, Message: "the versions file must be a mapping"}}
+	}
+
+	var findings []Finding
+	var list, fieldList *yamlv3.Node
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		key, value := doc.Content[i], doc.Content[i+1]
+		switch key.Value {
+		case "deprecatedVersions":
+			list = value
+		case "deprecatedFields":
+			fieldList = value
+		default:
+			findings = append(findings, Finding{Line: key.Line, Severity: SeverityError, Check: CheckSchema, Message: fmt.Sprintf("unknown field %q", key.Value)})
+		}
+	}
+	findings = append(findings, validateFieldList(fieldList)...)
+	if list == nil {
+		return append(findings, Finding{Line: doc.Line, Severity: SeverityError, Check: CheckSchema, Message: "missing field \"deprecatedVersions\""})
+	}
+	if list.Kind != yamlv3.SequenceNode {
+		if list.Tag == "!!null" {
+			return findings
+		}
+		return append(findings, Finding{Line: list.Line, Severity: SeverityError, Check: CheckSchema, Message: "\"deprecatedVersions\" must be a list"})
+	}
+
+	var rules []*validatedRule
+	for _, entry := range list.Content {
+		r, entryFindings := validateEntry(entry)
+		findings = append(findings, entryFindings...)
+		if r != nil {
+			rules = append(rules, r)
+		}
+	}
+	findings = append(findings, validateRules(rules)...)
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Line < findings[j].Line
+	})
+	return findings
+}
+
+// validateEntry checks the schema and the versions of a deprecated API version,
+// the rule is nil when it can't be decoded.
+func validateEntry(entry *yamlv3.Node) (*validatedRule, []Finding) {
+	if entry.Kind != yamlv3.MappingNode {
+		return nil, []Finding{{Line: entry.Line, Severity: SeverityError, Check: CheckSchema, Message: "a deprecated API version must be a mapping"}}
+	}
+	r := &validatedRule{Version: new(Version), line: entry.Line}
+	var findings []Finding
+	finding := func(line int, severity Severity, check, format string, args ...interface{}) {
+		findings = append(findings, Finding{
+			Line: line, Severity: severity, Check: check, Message: fmt.Sprintf(format, args...),
+			APIVersion: r.APIVersion, Kind: r.Kind, Component: r.Component,
+		})
+	}
+
+	// the rule is decoded first, so the findings of its fields identify it
+	decodeErr := entry.Decode(r.Version)
+	fields := stringFields(entry, versionFieldNames, finding)
+	if decodeErr != nil {
+		finding(entry.Line, SeverityError, CheckSchema, "%v", decodeErr)
+		return nil, findings
+	}
+
+	for _, name := range []string{"version", "kind"} {
+		if fields[name] == nil || fields[name].Value == "" {
+			finding(entry.Line, SeverityError, CheckSchema, "missing field %q", name)
+		}
+	}
+	if v := fields["version"]; v != nil && v.Value != "" && !apiVersionFormatPattern.MatchString(v.Value) {
+		finding(v.Line, SeverityError, CheckSchema, "invalid API version %q", v.Value)
+	}
+	if k := fields["kind"]; k != nil && k.Value != "" {
+		if _, err := path.Match(k.Value, ""); err != nil {
+			finding(k.Line, SeverityError, CheckSchema, "invalid kind pattern %q: %v", k.Value, err)
+		}
+	}
+	if r.IntroducedInVersion == "" && r.DeprecatedInVersion == "" && r.RemovedInVersion == "" {
+		finding(entry.Line, SeverityWarning, CheckSchema, "none of introducedInVersion, deprecatedInVersion and removedInVersion is set")
+	}
+
+	for _, f := range []struct {
+		name    string
+		version **semver.Version
+		line    *int
+	}{
+		{name: "introducedInVersion", version: &r.introduced, line: &r.introducedInLine},
+		{name: "deprecatedInVersion", version: &r.deprecated, line: &r.deprecatedInLine},
+		{name: "removedInVersion", version: &r.removed, line: &r.removedInLine},
+	} {
+		*f.version, *f.line = versionField(fields, f.name, finding)
+	}
+	if value := fields["replacementApi"]; value != nil {
+		r.replacementAPILine = value.Line
+	}
+	if value := fields["severity"]; value != nil && !IsRuleSeverity(value.Value) {
+		finding(value.Line, SeverityError, CheckSchema, "invalid severity %q, must be one of info, warning and critical", value.Value)
+	}
+	if value := fields["migrationGuide"]; value != nil && value.Value != "" && !isWebURL(value.Value) {
+		finding(value.Line, SeverityError, CheckSchema, "invalid migrationGuide %q, must be an http or https URL", value.Value)
+	}
+	return r, findings
+}
+
+// stringFields returns the fields of the entry by name, the unknown fields and the fields which are not strings
+// are reported as findings. The list fields are only checked, they are not returned.
+func stringFields(entry *yamlv3.Node, names map[string]bool, finding func(int, Severity, string, string, ...interface{})) map[string]*yamlv3.Node {
+	fields := make(map[string]*yamlv3.Node)
+	for i := 0; i+1 < len(entry.Content); i += 2 {
+		key, value := entry.Content[i], entry.Content[i+1]
+		if !names[key.Value] {
+			finding(key.Line, SeverityError, CheckSchema, "unknown field %q", key.Value)
+			continue
+		}
+		if listFieldNames[key.Value] {
+			checkStringList(key.Value, value, finding)
+			continue
+		}
+		if value.Kind != yamlv3.ScalarNode || (value.Tag != "!!str" && value.Tag != "!!null") {
+			if strings.HasSuffix(key.Value, "InVersion") && value.Kind == yamlv3.ScalarNode {
+				finding(value.Line, SeverityError, CheckSchema, "field %q must be a string such as v1.16.0, got %s", key.Value, value.Value)
+			} else {
+				finding(value.Line, SeverityError, CheckSchema, "field %q must be a string", key.Value)
+			}
+			continue
+		}
+		fields[key.Value] = value
+	}
+	return fields
+}
+
+// checkStringList reports the list field which is not a list of strings.
+func checkStringList(name string, value *yamlv3.Node, finding func(int, Severity, string, string, ...interface{})) {
+	if value.Kind == yamlv3.ScalarNode && value.Tag == "!!null" {
+		return
+	}
+	if value.Kind != yamlv3.SequenceNode {
+		finding(value.Line, SeverityError, CheckSchema, "field %q must be a list of strings", name)
+		return
+	}
+	for _, item := range value.Content {
+		if item.Kind != yamlv3.ScalarNode || item.Tag != "!!str" {
+			finding(item.Line, SeverityError, CheckSchema, "the items of %q must be strings", name)
+		}
+	}
+}
+
+// isWebURL checks if the value is an absolute http or https URL.
+func isWebURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// versionField parses the version field of an entry with its line, the version is nil when it's not set or invalid.
+func versionField(fields map[string]*yamlv3.Node, name string, finding func(int, Severity, string, string, ...interface{})) (*semver.Version, int) {
+	value := fields[name]
+	if value == nil || value.Value == "" {
+		return nil, 0
+	}
+	v, err := semver.NewVersion(value.Value)
+	if err != nil {
+		finding(value.Line, SeverityError, CheckVersion, "invalid %s %q: %v", name, value.Value, err)
+		return nil, value.Line
+	}
+	if !canonicalVersionPattern.MatchString(value.Value) {
+		finding(value.Line, SeverityWarning, CheckVersionFormat, "%s %q is not in the vMAJOR.MINOR.PATCH format, such as %s",
+			name, value.Value, formatCanonical(v))
+	}
+	return v, value.Line
+}
+
+// validateFieldList checks the deprecated fields and annotations, the list is nil when the versions file doesn't have any.
+func validateFieldList(list *yamlv3.Node) []Finding {
+	if list == nil || list.Tag == "!!null" {
+		return nil
+	}
+	if list.Kind != yamlv3.SequenceNode {
+		return []Finding{{Line: list.Line, Severity: SeverityError, Check: CheckSchema, Message: "\"deprecatedFields\" must be a list"}}
+	}
+	var findings []Finding
+	first := make(map[fieldKey]*validatedField, len(list.Content))
+	for _, entry := range list.Content {
+		f, entryFindings := validateFieldEntry(entry)
+		findings = append(findings, entryFindings...)
+		if f == nil {
+			continue
+		}
+		finding := Finding{APIVersion: f.APIVersion, Kind: f.Kind, Component: f.Component, Field: f.Name()}
+		if f.deprecated != nil && f.removed != nil && f.removed.LessThan(f.deprecated) {
+			finding.Line, finding.Severity, finding.Check = f.removedInLine, SeverityError, CheckRemovedBeforeDeprecated
+			finding.Message = fmt.Sprintf("%s %s is removed in %s before it is deprecated in %s", f.Kind, f.Name(), f.RemovedInVersion, f.DeprecatedInVersion)
+			findings = append(findings, finding)
+		}
+		key := fieldKeyOf(f.FieldVersion)
+		existing, ok := first[key]
+		if !ok {
+			first[key] = f
+			continue
+		}
+		finding.Line = f.line
+		if reflect.DeepEqual(withFieldComponent(existing.FieldVersion), withFieldComponent(f.FieldVersion)) {
+			finding.Severity, finding.Check = SeverityWarning, CheckDuplicate
+			finding.Message = fmt.Sprintf("%s %s is already listed on line %d", f.Kind, f.Name(), existing.line)
+		} else {
+			finding.Severity, finding.Check = SeverityError, CheckConflict
+			finding.Message = fmt.Sprintf("%s %s is already listed differently on line %d, this entry is ignored", f.Kind, f.Name(), existing.line)
+		}
+		findings = append(findings, finding)
+	}
+	return findings
+}
+
+// validateFieldEntry checks the schema and the versions of a deprecated field or annotation,
+// the field is nil when it can't be decoded.
+func validateFieldEntry(entry *yamlv3.Node) (*validatedField, []Finding) {
+	if entry.Kind != yamlv3.MappingNode {
+		return nil, []Finding{{Line: entry.Line, Severity: SeverityError, Check: CheckSchema, Message: "a deprecated field or annotation must be a mapping"}}
+	}
+	f := &validatedField{FieldVersion: new(FieldVersion), line: entry.Line}
+	var findings []Finding
+	finding := func(line int, severity Severity, check, format string, args ...interface{}) {
+		findings = append(findings, Finding{
+			Line: line, Severity: severity, Check: check, Message: fmt.Sprintf(format, args...),
+			APIVersion: f.APIVersion, Kind: f.Kind, Component: f.Component, Field: f.Name(),
+		})
+	}
+
+	decodeErr := entry.Decode(f.FieldVersion)
+	fields := stringFields(entry, fieldVersionFieldNames, finding)
+	if decodeErr != nil {
+		finding(entry.Line, SeverityError, CheckSchema, "%v", decodeErr)
+		return nil, findings
+	}
+	lineOf := func(name string) int {
+		if value := fields[name]; value != nil {
+			return value.Line
+		}
+		return entry.Line
+	}
+
+	if f.Kind == "" {
+		finding(entry.Line, SeverityError, CheckSchema, "missing field %q", "kind")
+	} else if _, err := path.Match(f.Kind, ""); err != nil {
+		finding(lineOf("kind"), SeverityError, CheckSchema, "invalid kind pattern %q: %v", f.Kind, err)
+	}
+	if f.APIVersion != "" && !apiVersionFormatPattern.MatchString(f.APIVersion) {
+		finding(lineOf("version"), SeverityError, CheckSchema, "invalid API version %q", f.APIVersion)
+	}
+	if (f.Field == "") == (f.Annotation == "") {
+		finding(entry.Line, SeverityError, CheckSchema, "exactly one of field and annotation must be set")
+	} else if _, err := path.Match(f.Annotation, ""); err != nil {
+		finding(lineOf("annotation"), SeverityError, CheckSchema, "invalid annotation pattern %q: %v", f.Annotation, err)
+	}
+	if f.DeprecatedInVersion == "" && f.RemovedInVersion == "" {
+		finding(entry.Line, SeverityWarning, CheckSchema, "none of deprecatedInVersion and removedInVersion is set")
+	}
+	f.deprecated, f.deprecatedInLine = versionField(fields, "deprecatedInVersion", finding)
+	f.removed, f.removedInLine = versionField(fields, "removedInVersion", finding)
+	if f.Replacement != "" && f.Replacement == f.Name() {
+		finding(lineOf("replacement"), SeverityError, CheckSchema, "%s %s is replaced by itself", f.Kind, f.Name())
+	}
+	return f, findings
+}
+
+// withFieldComponent returns a copy of the deprecated field or annotation with its component set, so an empty
+// component is the same as k8s.
+func withFieldComponent(f *FieldVersion) FieldVersion {
+	c := *f
+	c.Component = componentOf(c.Component)
+	return c
+}
+
+// validateRules checks the rules against each other.
+func validateRules(rules []*validatedRule) []Finding {
+	var findings []Finding
+	first := make(map[ruleKey]*validatedRule, len(rules))
+	for _, r := range rules {
+		finding := func(line int, severity Severity, check, format string, args ...interface{}) {
+			findings = append(findings, Finding{
+				Line: line, Severity: severity, Check: check, Message: fmt.Sprintf(format, args...),
+				APIVersion: r.APIVersion, Kind: r.Kind, Component: r.Component,
+			})
+		}
+
+		if r.deprecated != nil && r.removed != nil && r.removed.LessThan(r.deprecated) {
+			finding(r.removedInLine, SeverityError, CheckRemovedBeforeDeprecated, "%s %s is removed in %s before it is deprecated in %s",
+				r.APIVersion, r.Kind, r.RemovedInVersion, r.DeprecatedInVersion)
+		}
+		if r.introduced != nil {
+			if r.deprecated != nil && r.deprecated.LessThan(r.introduced) {
+				finding(r.introducedInLine, SeverityError, CheckIntroducedAfterDeprecation, "%s %s is introduced in %s after it is deprecated in %s",
+					r.APIVersion, r.Kind, r.IntroducedInVersion, r.DeprecatedInVersion)
+			} else if r.removed != nil && !r.introduced.LessThan(r.removed) {
+				finding(r.introducedInLine, SeverityError, CheckIntroducedAfterDeprecation, "%s %s is introduced in %s once it is removed in %s",
+					r.APIVersion, r.Kind, r.IntroducedInVersion, r.RemovedInVersion)
+			}
+		}
+		if r.ReplacementAPI != "" && r.ReplacementAPI == r.APIVersion {
+			finding(r.replacementAPILine, SeverityError, CheckSchema, "%s %s is replaced by itself", r.APIVersion, r.Kind)
+		}
+
+		key := keyOf(r.Version)
+		existing, ok := first[key]
+		if !ok {
+			first[key] = r
+			continue
+		}
+		if reflect.DeepEqual(withComponent(existing.Version), withComponent(r.Version)) {
+			finding(r.line, SeverityWarning, CheckDuplicate, "%s %s is already listed on line %d", r.APIVersion, r.Kind, existing.line)
+		} else {
+			finding(r.line, SeverityError, CheckConflict, "%s %s is already listed differently on line %d, this entry is ignored",
+				r.APIVersion, r.Kind, existing.line)
+		}
+	}
+
+	for _, r := range rules {
+		if r.ReplacementAPI == "" || r.ReplacementAPI == r.APIVersion || first[keyOf(r.Version)] != r {
+			continue
+		}
+		replacement, ok := first[keyOf(&Version{APIVersion: r.ReplacementAPI, Kind: r.Kind, Component: r.Component})]
+		if !ok || (replacement.deprecated == nil && replacement.removed == nil) {
+			continue
+		}
+		// A replacement deprecated once the API version is removed, such as the successive betas of a group, is
+		// the one to migrate to until then
+		if r.removed != nil && !replacementDeprecatedBefore(replacement, r.removed) {
+			continue
+		}
+		since := replacement.DeprecatedInVersion
+		if since == "" {
+			since = replacement.RemovedInVersion
+		}
+		findings = append(findings, Finding{
+			Line: r.replacementAPILine, Severity: SeverityWarning, Check: CheckDeprecatedReplacement,
+			Message: fmt.Sprintf("the replacement %s %s is deprecated itself since %s (line %d)",
+				r.ReplacementAPI, r.Kind, since, replacement.line),
+			APIVersion: r.APIVersion, Kind: r.Kind, Component: r.Component,
+		})
+	}
+	return findings
+}
+
+// formatCanonical formats the version in the vMAJOR.MINOR.PATCH format.
+func formatCanonical(v *semver.Version) string {
+	segments := v.Segments()
+	return fmt.Sprintf("v%d.%d.%d", segments[0], segments[1], segments[2])
+}
+
+// yamlErrorLine returns the line of a YAML syntax error, 0 when it's unknown.
+func yamlErrorLine(err error) int {
+	if m := yamlErrorLinePattern.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return line
+	}
+	return 0
+}
+
+// HasErrors checks if any of the findings is an error.
+func HasErrors(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// replacementDeprecatedBefore checks if the replacement is deprecated, or removed without deprecation, before the version.
+func replacementDeprecatedBefore(replacement *validatedRule, version *semver.Version) bool {
+	since := replacement.deprecated
+	if since == nil {
+		since = replacement.removed
+	}
+	return since.LessThan(version)
+}
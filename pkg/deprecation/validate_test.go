@@ -0,0 +1,137 @@
+package deprecation
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	content, err := ioutil.ReadFile("testdata/invalid-versions.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	type finding struct {
+		line  int
+		check string
+	}
+	expected := []finding{
+		{line: 5, check: CheckSchema},
+		{line: 6, check: CheckDeprecatedReplacement},
+		{line: 9, check: CheckVersionFormat},
+		{line: 12, check: CheckDuplicate},
+		{line: 14, check: CheckVersionFormat},
+		{line: 17, check: CheckConflict},
+		{line: 25, check: CheckRemovedBeforeDeprecated},
+		{line: 26, check: CheckSchema},
+		{line: 27, check: CheckSchema},
+		{line: 29, check: CheckVersion},
+		{line: 30, check: CheckSchema},
+		{line: 30, check: CheckSchema},
+		{line: 33, check: CheckIntroducedAfterDeprecation},
+		{line: 36, check: CheckSchema},
+		{line: 43, check: CheckRemovedBeforeDeprecated},
+		{line: 44, check: CheckConflict},
+		{line: 46, check: CheckVersionFormat},
+		{line: 47, check: CheckSchema},
+		{line: 47, check: CheckSchema},
+	}
+	got := Validate(content)
+	if len(got) != len(expected) {
+		for _, f := range got {
+			t.Logf("%s", f)
+		}
+		t.Fatalf("Expected %d findings, got %d", len(expected), len(got))
+	}
+	for i, f := range got {
+		if f.Line != expected[i].line || f.Check != expected[i].check {
+			t.Fatalf("Finding %d: expected %+v, got: %s", i, expected[i], f)
+		}
+	}
+	if !HasErrors(got) {
+		t.Fatalf("Expected the findings to have errors")
+	}
+}
+
+func TestValidateSyntax(t *testing.T) {
+	tests := map[string]int{
+		"deprecatedVersions: [":              1,
+		"- version: v1":                      1,
+		"deprecatedVersion: []":              1,
+		"deprecatedVersions:\n  version: v1": 2,
+		"deprecatedVersions: []\ndeprecatedFields:\n  kind: Ingress": 3,
+	}
+	for content, line := range tests {
+		got := Validate([]byte(content))
+		if len(got) == 0 || got[0].Line != line || got[0].Severity != SeverityError {
+			t.Fatalf("Expected an error on line %d for %q, got: %v", line, content, got)
+		}
+	}
+}
+
+func TestValidateGuidance(t *testing.T) {
+	content := `deprecatedVersions:
+  - version: extensions/v1beta1
+    kind: Ingress
+    removedInVersion: v1.22.0
+    severity: critical
+    migrationGuide: https://kubernetes.io/docs/reference/using-api/deprecation-guide/#ingress-v122
+    fieldChanges:
+      - spec.backend is renamed to spec.defaultBackend
+  - version: networking.k8s.io/v1beta1
+    kind: Ingress
+    removedInVersion: v1.22.0
+    severity: urgent
+    migrationGuide: kubernetes.io/docs
+  - version: batch/v1beta1
+    kind: CronJob
+    removedInVersion: v1.25.0
+    fieldChanges:
+      - 25
+`
+	expected := []int{12, 13, 18}
+	got := Validate([]byte(content))
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d findings, got: %v", len(expected), got)
+	}
+	for i, f := range got {
+		if f.Line != expected[i] || f.Check != CheckSchema || f.Severity != SeverityError {
+			t.Fatalf("Finding %d: expected a schema error on line %d, got: %s", i, expected[i], f)
+		}
+	}
+}
+
+func TestValidateReplacementChain(t *testing.T) {
+	content := `deprecatedVersions:
+  - version: flowcontrol.apiserver.k8s.io/v1beta1
+    kind: FlowSchema
+    deprecatedInVersion: v1.23.0
+    removedInVersion: v1.26.0
+    replacementApi: flowcontrol.apiserver.k8s.io/v1beta2
+  - version: flowcontrol.apiserver.k8s.io/v1beta2
+    kind: FlowSchema
+    deprecatedInVersion: v1.26.0
+    removedInVersion: v1.29.0
+    replacementApi: flowcontrol.apiserver.k8s.io/v1beta3
+  - version: flowcontrol.apiserver.k8s.io/v1beta3
+    kind: FlowSchema
+    deprecatedInVersion: v1.27.0
+    removedInVersion: v1.32.0
+`
+	got := Validate([]byte(content))
+	if len(got) != 1 || got[0].Line != 11 || got[0].Check != CheckDeprecatedReplacement {
+		t.Fatalf("Expected only the replacement deprecated before the removal to be reported, got: %v", got)
+	}
+}
+
+func TestValidateVersionsFile(t *testing.T) {
+	content, err := ioutil.ReadFile(versionsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if findings := Validate(content); len(findings) != 0 {
+		for _, f := range findings {
+			t.Logf("%s", f)
+		}
+		t.Fatalf("Expected the versions file to have no findings")
+	}
+}
@@ -19,13 +19,50 @@ package deprecation
 // Version describes the deprecated API version for different kinds
 type Version struct {
 	// APIVersion is the name of the API version used by specific kind.
-	APIVersion string `json:"version" yaml:"version"`
+	APIVersion string `json:"version" yaml:"version" jsonschema:"required"`
 	// Kind is the Object type such as "Deployment" or "Ingress"
-	Kind string `json:"kind" yaml:"kind"`
+	Kind string `json:"kind" yaml:"kind" jsonschema:"required"`
+	// Kubernetes version in which the API version is introduced in, older versions don't serve it
+	IntroducedInVersion string `json:"introducedInVersion,omitempty" yaml:"introducedInVersion,omitempty"`
 	// Kubernetes version in which the API version is deprecated in
 	DeprecatedInVersion string `json:"deprecatedInVersion" yaml:"deprecatedInVersion"`
 	// Kubernetes version in which the API version is removed in
 	RemovedInVersion string `json:"removedInVersion" yaml:"removedInVersion"`
 	// ReplacementAPI is the new supported API version
 	ReplacementAPI string `json:"replacementApi" yaml:"replacementApi"`
+	// Severity is how urgent the migration away from the API version is: info, warning or critical
+	Severity string `json:"severity,omitempty" yaml:"severity,omitempty"`
+	// MigrationNote is a short note on how to migrate to the replacement
+	MigrationNote string `json:"migrationNote,omitempty" yaml:"migrationNote,omitempty"`
+	// MigrationGuide is the URL of the upstream migration guide
+	MigrationGuide string `json:"migrationGuide,omitempty" yaml:"migrationGuide,omitempty"`
+	// FieldChanges are the notable field changes between the API version and the replacement
+	FieldChanges []string `json:"fieldChanges,omitempty" yaml:"fieldChanges,omitempty"`
+	// Component is the name of the component serving the API version such as "k8s" or "cert-manager",
+	// its versions are compared with the installed version of the component. Empty means "k8s".
+	Component string `json:"component,omitempty" yaml:"component,omitempty"`
+}
+
+// The severities of a rule, from the least to the most urgent.
+const (
+	RuleSeverityInfo     = "info"
+	RuleSeverityWarning  = "warning"
+	RuleSeverityCritical = "critical"
+)
+
+// ruleSeverities ranks the severities of the rules, an empty severity ranks lowest.
+var ruleSeverities = map[string]int{"": 0, RuleSeverityInfo: 1, RuleSeverityWarning: 2, RuleSeverityCritical: 3}
+
+// IsRuleSeverity checks if the severity is empty or one of info, warning and critical.
+func IsRuleSeverity(severity string) bool {
+	_, ok := ruleSeverities[severity]
+	return ok
+}
+
+// MaxSeverity returns the most urgent of the two severities.
+func MaxSeverity(a, b string) string {
+	if ruleSeverities[b] > ruleSeverities[a] {
+		return b
+	}
+	return a
 }
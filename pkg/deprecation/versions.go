@@ -20,4 +20,11 @@ package deprecation
 type Versions struct {
 	// DeprecatedVersions are a list of deprecated API versions.
 	DeprecatedVersions []*Version `json:"deprecatedVersions" yaml:"deprecatedVersions"`
+	// DeprecatedFields are a list of deprecated fields and annotations.
+	DeprecatedFields []*FieldVersion `json:"deprecatedFields,omitempty" yaml:"deprecatedFields,omitempty"`
+}
+
+// IsEmpty checks if the versions file has neither deprecated API versions nor deprecated fields and annotations.
+func (v *Versions) IsEmpty() bool {
+	return len(v.DeprecatedVersions) == 0 && len(v.DeprecatedFields) == 0
 }
@@ -0,0 +1,268 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/wayfair-incubator/k8s-used-api-versions/api/v1beta1"
+	"github.com/wayfair-incubator/k8s-used-api-versions/pkg/deprecation"
+)
+
+const (
+	// jsonSchemaDraft is the JSON Schema dialect of the generated schemas
+	jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+	// jsonSchemaTag is the struct tag of the schema options, "required" is the only option
+	jsonSchemaTag = "jsonschema"
+)
+
+// JSONSchema is the subset of a JSON Schema generated from the Go types.
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	ID                   string                 `json:"$id,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Const                interface{}            `json:"const,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+}
+
+// VersionsSchema returns the JSON Schema of a versions file.
+func VersionsSchema() *JSONSchema {
+	s := SchemaOf(reflect.TypeOf(deprecation.Versions{}))
+	s.Schema = jsonSchemaDraft
+	s.ID = "versions.schema.json"
+	s.Title = "Deprecated API versions"
+	return s
+}
+
+// UsedApiVersionsSchema returns the JSON Schema of a UsedApiVersions manifest. The metadata is only checked
+// to be an object with a name, the API server validates the rest of it.
+func UsedApiVersionsSchema() *JSONSchema {
+	closed := false
+	return &JSONSchema{
+		Schema: jsonSchemaDraft,
+		ID:     "usedapiversions.schema.json",
+		Title:  "UsedApiVersions",
+		Type:   "object",
+		Properties: map[string]*JSONSchema{
+			"apiVersion": {Type: "string", Const: v1beta1.GroupVersion.String()},
+			"kind":       {Type: "string", Const: "UsedApiVersions"},
+			"metadata": {
+				Type:       "object",
+				Properties: map[string]*JSONSchema{"name": {Type: "string"}, "namespace": {Type: "string"}},
+				Required:   []string{"name"},
+			},
+			"spec": SchemaOf(reflect.TypeOf(v1beta1.UsedApiVersionsSpec{})),
+		},
+		Required:             []string{"apiVersion", "kind", "metadata", "spec"},
+		AdditionalProperties: &closed,
+	}
+}
+
+// SchemaOf returns the JSON Schema of a Go type from its json tags. The struct fields tagged
+// `jsonschema:"required"` are required, and the structs don't allow unknown fields like a strict unmarshal.
+func SchemaOf(t reflect.Type) *JSONSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &JSONSchema{Type: "array", Items: SchemaOf(t.Elem())}
+	case reflect.Map:
+		return &JSONSchema{Type: "object"}
+	case reflect.Struct:
+		closed := false
+		s := &JSONSchema{Type: "object", Properties: make(map[string]*JSONSchema), AdditionalProperties: &closed}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if field.PkgPath != "" || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			s.Properties[name] = SchemaOf(field.Type)
+			if field.Tag.Get(jsonSchemaTag) == "required" {
+				s.Required = append(s.Required, name)
+			}
+		}
+		return s
+	}
+	return &JSONSchema{}
+}
+
+// Validate validates a value decoded from JSON or YAML against the schema, and returns the errors prefixed
+// by the path of the invalid value, such as "deprecatedVersions[3].kind".
+func (s *JSONSchema) Validate(value interface{}) []string {
+	var errs []string
+	s.validate("", normalize(value), &errs)
+	return errs
+}
+
+// normalize converts a value decoded from YAML to the types decoded from JSON, the map keys to strings
+// and the numbers to float64.
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[key] = normalize(item)
+		}
+		return m
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[fmt.Sprint(key)] = normalize(item)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, item := range v {
+			l[i] = normalize(item)
+		}
+		return l
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	}
+	return value
+}
+
+func (s *JSONSchema) validate(path string, value interface{}, errs *[]string) {
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, fmt.Sprintf("%s: %s", pathOrRoot(path), fmt.Sprintf(format, args...)))
+	}
+	if s.Type != "" && !hasType(value, s.Type) {
+		fail("must be %s %s, got %s", article(s.Type), s.Type, typeOf(value))
+		return
+	}
+	if s.Const != nil && !reflect.DeepEqual(value, s.Const) {
+		fail("must be %v", s.Const)
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				fail("missing required field %q", name)
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			property, ok := s.Properties[key]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					fail("unknown field %q", key)
+				}
+				continue
+			}
+			property.validate(joinPath(path, key), v[key], errs)
+		}
+	case []interface{}:
+		if s.Items == nil {
+			return
+		}
+		for i, item := range v {
+			s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, errs)
+		}
+	}
+}
+
+// hasType checks if a value decoded from JSON has the JSON Schema type.
+func hasType(value interface{}, t string) bool {
+	switch t {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == float64(int64(n))
+	}
+	return true
+}
+
+// typeOf returns the JSON type of a value decoded from JSON.
+func typeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	case string:
+		return fmt.Sprintf("%q", v)
+	}
+	b, _ := json.Marshal(value)
+	return string(b)
+}
+
+func article(t string) string {
+	if t == "object" || t == "array" || t == "integer" {
+		return "an"
+	}
+	return "a"
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return path
+}
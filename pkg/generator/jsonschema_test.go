@@ -0,0 +1,116 @@
+package generator
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+func TestSchemaFilesUpToDate(t *testing.T) {
+	for file, schema := range map[string]*JSONSchema{
+		"../../config/schema/versions.schema.json":        VersionsSchema(),
+		"../../config/schema/usedapiversions.schema.json": UsedApiVersionsSchema(),
+	} {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", file, err)
+		}
+		expected, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			t.Fatalf("failed to marshal the schema: %v", err)
+		}
+		if string(content) != string(expected)+"\n" {
+			t.Fatalf("%s is outdated, run make schema", file)
+		}
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   *JSONSchema
+		document string
+		expected []string
+	}{
+		{
+			name:   "valid versions file",
+			schema: VersionsSchema(),
+			document: `
+deprecatedVersions:
+  - version: extensions/v1beta1
+    kind: Ingress
+    deprecatedInVersion: v1.14.0
+    removedInVersion: v1.22.0
+    replacementApi: networking.k8s.io/v1
+`,
+		},
+		{
+			name:   "invalid versions file",
+			schema: VersionsSchema(),
+			document: `
+deprecatedVersions:
+  - kind: Ingress
+    removedInVersion: 1.22
+    replacement: networking.k8s.io/v1
+  - version: apps/v1beta1
+    kind: Deployment
+extra: true
+`,
+			expected: []string{
+				`deprecatedVersions[0]: missing required field "version"`,
+				`deprecatedVersions[0].removedInVersion: must be a string, got 1.22`,
+				`deprecatedVersions[0]: unknown field "replacement"`,
+				`(root): unknown field "extra"`,
+			},
+		},
+		{
+			name:   "valid manifest",
+			schema: UsedApiVersionsSchema(),
+			document: `
+apiVersion: api-version.wayfair.com/v1beta1
+kind: UsedApiVersions
+metadata:
+  name: ingress-operator
+spec:
+  usedApiVersions:
+    - apiVersion: extensions/v1beta1
+      kind: Ingress
+`,
+		},
+		{
+			name:   "invalid manifest",
+			schema: UsedApiVersionsSchema(),
+			document: `
+apiVersion: api-version.wayfair.com/v1
+kind: UsedApiVersions
+metadata: {}
+spec:
+  usedApiVersions:
+    apiVersion: extensions/v1beta1
+`,
+			expected: []string{
+				`apiVersion: must be api-version.wayfair.com/v1beta1`,
+				`metadata: missing required field "name"`,
+				`spec.usedApiVersions: must be an array, got an object`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value interface{}
+			if err := yamlv3.Unmarshal([]byte(tt.document), &value); err != nil {
+				t.Fatalf("failed to parse the document: %v", err)
+			}
+			errs := tt.schema.Validate(value)
+			if len(errs) == 0 && len(tt.expected) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(errs, tt.expected) {
+				t.Fatalf("expected %q, got %q", tt.expected, errs)
+			}
+		})
+	}
+}
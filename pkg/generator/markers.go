@@ -0,0 +1,308 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package generator generates versions files from the Kubernetes sources and specs.
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	semver "github.com/hashicorp/go-version"
+
+	"github.com/wayfair-incubator/k8s-used-api-versions/pkg/deprecation"
+)
+
+const (
+	lifecycleMarker = "+k8s:prerelease-lifecycle-gen:"
+	groupNameMarker = "+groupName="
+	// lifecycleDefaultReleases is the number of releases between introduction, deprecation and removal
+	// when the markers don't set them, as defaulted by prerelease-lifecycle-gen.
+	lifecycleDefaultReleases = 3
+)
+
+// lifecycle is the prerelease lifecycle of a kind read from its markers.
+type lifecycle struct {
+	introduced  *semver.Version
+	deprecated  *semver.Version
+	removed     *semver.Version
+	replacement string
+}
+
+// FromLifecycleMarkers walks a checkout of k8s.io/api and returns the deprecated API versions of every kind
+// with +k8s:prerelease-lifecycle-gen markers, with the version they are introduced in. When the deprecated or removed markers are not set,
+// they default to 3 releases after the introduced and deprecated versions like prerelease-lifecycle-gen does.
+// The API versions are sorted by group, version and kind.
+func FromLifecycleMarkers(root string) (*deprecation.Versions, error) {
+	versions := new(deprecation.Versions)
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if name := info.Name(); path != root && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata") {
+			return filepath.SkipDir
+		}
+		deps, err := packageLifecycles(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		versions.DeprecatedVersions = append(versions.DeprecatedVersions, deps...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortVersions(versions)
+	return versions, nil
+}
+
+// packageLifecycles returns the deprecated API versions of the API package in the directory,
+// nothing when the directory is not an API package.
+func packageLifecycles(dir string) ([]*deprecation.Version, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	var deps []*deprecation.Version
+	for _, pkg := range pkgs {
+		group, ok := groupName(pkg)
+		if !ok {
+			continue
+		}
+		apiVersion := filepath.Base(dir)
+		if group != "" {
+			apiVersion = group + "/" + apiVersion
+		}
+		for _, f := range pkg.Files {
+			kinds, err := kindLifecycles(f)
+			if err != nil {
+				return nil, err
+			}
+			for _, kind := range kinds {
+				if dep := kind.lifecycle.version(apiVersion, kind.name); dep != nil {
+					deps = append(deps, dep)
+				}
+			}
+		}
+	}
+	return deps, nil
+}
+
+// groupName returns the API group of the package from its GroupName constant or +groupName marker,
+// false when the package is not an API package.
+func groupName(pkg *ast.Package) (string, bool) {
+	for _, f := range pkg.Files {
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				value := spec.(*ast.ValueSpec)
+				for i, name := range value.Names {
+					if name.Name != "GroupName" || i >= len(value.Values) {
+						continue
+					}
+					if lit, ok := value.Values[i].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+						group, err := strconv.Unquote(lit.Value)
+						return group, err == nil
+					}
+				}
+			}
+		}
+	}
+	for _, f := range pkg.Files {
+		for _, c := range f.Comments {
+			for _, line := range c.List {
+				text := strings.TrimSpace(strings.TrimPrefix(line.Text, "//"))
+				if strings.HasPrefix(text, groupNameMarker) {
+					return strings.TrimPrefix(text, groupNameMarker), true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// kindLifecycle is the lifecycle of a kind.
+type kindLifecycle struct {
+	name      string
+	lifecycle lifecycle
+}
+
+// kindLifecycles returns the lifecycle of the kinds of the file which have lifecycle markers.
+// The markers are read from the comments between the previous declaration and the type,
+// since they are usually separated from the type's doc comment by a blank line.
+// Only the types with object metadata are kinds, so the lists and the options are skipped.
+func kindLifecycles(f *ast.File) ([]kindLifecycle, error) {
+	var kinds []kindLifecycle
+	previous := f.Name.End()
+	for _, decl := range f.Decls {
+		start, end := previous, decl.Pos()
+		previous = decl.End()
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE || len(gen.Specs) != 1 {
+			continue
+		}
+		spec := gen.Specs[0].(*ast.TypeSpec)
+		if !hasObjectMeta(spec) {
+			continue
+		}
+		var markers []string
+		for _, c := range f.Comments {
+			if c.Pos() < start || c.End() > end {
+				continue
+			}
+			for _, line := range c.List {
+				text := strings.TrimSpace(strings.TrimPrefix(line.Text, "//"))
+				if strings.HasPrefix(text, lifecycleMarker) {
+					markers = append(markers, strings.TrimPrefix(text, lifecycleMarker))
+				}
+			}
+		}
+		if len(markers) == 0 {
+			continue
+		}
+		l, err := parseLifecycle(markers)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", spec.Name.Name, err)
+		}
+		kinds = append(kinds, kindLifecycle{name: spec.Name.Name, lifecycle: l})
+	}
+	return kinds, nil
+}
+
+// hasObjectMeta checks if the type is a struct embedding metav1.ObjectMeta.
+func hasObjectMeta(spec *ast.TypeSpec) bool {
+	s, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return false
+	}
+	for _, field := range s.Fields.List {
+		if len(field.Names) != 0 {
+			continue
+		}
+		if sel, ok := field.Type.(*ast.SelectorExpr); ok && sel.Sel.Name == "ObjectMeta" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseLifecycle parses the lifecycle markers without their prefix such as "introduced=1.8".
+func parseLifecycle(markers []string) (lifecycle, error) {
+	var l lifecycle
+	for _, marker := range markers {
+		parts := strings.SplitN(marker, "=", 2)
+		if len(parts) != 2 {
+			return l, fmt.Errorf("invalid marker %s%s", lifecycleMarker, marker)
+		}
+		name, value := parts[0], strings.TrimSpace(parts[1])
+		var err error
+		switch name {
+		case "introduced":
+			l.introduced, err = semver.NewVersion(value)
+		case "deprecated":
+			l.deprecated, err = semver.NewVersion(value)
+		case "removed":
+			l.removed, err = semver.NewVersion(value)
+		case "replacement":
+			l.replacement, err = replacementAPIVersion(value)
+		default:
+			err = fmt.Errorf("unknown marker")
+		}
+		if err != nil {
+			return l, fmt.Errorf("invalid marker %s%s: %w", lifecycleMarker, marker, err)
+		}
+	}
+	return l, nil
+}
+
+// replacementAPIVersion returns the API version of a replacement marker such as "apps,v1,Deployment".
+func replacementAPIVersion(value string) (string, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+		return "", fmt.Errorf("expected group,version,kind")
+	}
+	if parts[0] == "" {
+		return parts[1], nil
+	}
+	return parts[0] + "/" + parts[1], nil
+}
+
+// version returns the deprecated API version of the kind, nil when the kind has no lifecycle marker. The missing
+// deprecated and removed versions are defaulted from the previous one, so an introduced kind is always returned.
+func (l lifecycle) version(apiVersion, kind string) *deprecation.Version {
+	if l.introduced == nil && l.deprecated == nil && l.removed == nil {
+		return nil
+	}
+	deprecated, removed := l.deprecated, l.removed
+	if deprecated == nil && l.introduced != nil {
+		deprecated = addReleases(l.introduced, lifecycleDefaultReleases)
+	}
+	if removed == nil && deprecated != nil {
+		removed = addReleases(deprecated, lifecycleDefaultReleases)
+	}
+	return &deprecation.Version{
+		APIVersion:          apiVersion,
+		Kind:                kind,
+		IntroducedInVersion: formatVersion(l.introduced),
+		DeprecatedInVersion: formatVersion(deprecated),
+		RemovedInVersion:    formatVersion(removed),
+		ReplacementAPI:      l.replacement,
+	}
+}
+
+// addReleases returns the version of the minor release after specific number of releases.
+func addReleases(v *semver.Version, releases int) *semver.Version {
+	segments := v.Segments()
+	next, _ := semver.NewVersion(fmt.Sprintf("%d.%d.0", segments[0], segments[1]+releases))
+	return next
+}
+
+// formatVersion formats the version like the versions file, such as v1.16.0.
+func formatVersion(v *semver.Version) string {
+	if v == nil {
+		return ""
+	}
+	segments := v.Segments()
+	return fmt.Sprintf("v%d.%d.%d", segments[0], segments[1], segments[2])
+}
+
+// sortVersions sorts the deprecated API versions by group, version and kind.
+func sortVersions(v *deprecation.Versions) {
+	sort.SliceStable(v.DeprecatedVersions, func(i, j int) bool {
+		a, b := v.DeprecatedVersions[i], v.DeprecatedVersions[j]
+		if a.APIVersion != b.APIVersion {
+			return a.APIVersion < b.APIVersion
+		}
+		return a.Kind < b.Kind
+	})
+}
@@ -0,0 +1,40 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/wayfair-incubator/k8s-used-api-versions/pkg/deprecation"
+)
+
+func TestFromLifecycleMarkers(t *testing.T) {
+	got, err := FromLifecycleMarkers("testdata/api")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []*deprecation.Version{
+		{APIVersion: "apps/v1beta1", Kind: "Deployment", IntroducedInVersion: "v1.6.0", DeprecatedInVersion: "v1.8.0", RemovedInVersion: "v1.16.0", ReplacementAPI: "apps/v1"},
+		{APIVersion: "batch/v1beta1", Kind: "CronJob", IntroducedInVersion: "v1.8.0", DeprecatedInVersion: "v1.11.0", RemovedInVersion: "v1.14.0"},
+		{APIVersion: "v1", Kind: "Event", IntroducedInVersion: "v1.1.0", DeprecatedInVersion: "v1.19.0", RemovedInVersion: "v1.22.0", ReplacementAPI: "events.k8s.io/v1"},
+	}
+	if !reflect.DeepEqual(got.DeprecatedVersions, expected) {
+		for _, v := range got.DeprecatedVersions {
+			t.Logf("%+v", v)
+		}
+		t.Fatalf("Unexpected deprecated API versions")
+	}
+}
+
+func TestParseLifecycleErrors(t *testing.T) {
+	tests := [][]string{
+		{"introduced"},
+		{"introduced=one"},
+		{"replacement=apps,v1"},
+		{"unknown=1.8"},
+	}
+	for _, markers := range tests {
+		if _, err := parseLifecycle(markers); err == nil {
+			t.Fatalf("Expected an error for %v", markers)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	semver "github.com/hashicorp/go-version"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/wayfair-incubator/k8s-used-api-versions/pkg/deprecation"
+)
+
+// deprecatedByPattern matches the replacement in the description of a deprecated schema, such as
+// "DEPRECATED - This group version of Deployment is deprecated by apps/v1beta2/Deployment."
+var deprecatedByPattern = regexp.MustCompile(`deprecated by ((?:[a-z0-9.-]+/)?v[0-9a-z]+)/[A-Za-z]+`)
+
+// Spec is the API of a Kubernetes release read from its OpenAPI documents.
+type Spec struct {
+	// Release is the Kubernetes release, such as v1.22.0
+	Release *semver.Version
+	// Kinds are the served kinds
+	Kinds map[schema.GroupVersionKind]*SpecKind
+}
+
+// SpecKind is a kind served by a Kubernetes release.
+type SpecKind struct {
+	// Deprecated is true when the schema of the kind is deprecated
+	Deprecated bool
+	// Replacement is the API version replacing a deprecated kind, when the description mentions it
+	Replacement string
+}
+
+// openAPIDocument is the part of a swagger 2.0 or OpenAPI 3 document used to find the served kinds.
+type openAPIDocument struct {
+	Info struct {
+		Version string `json:"version"`
+	} `json:"info"`
+	Paths map[string]map[string]json.RawMessage `json:"paths"`
+	// Definitions are the schemas of a swagger 2.0 document
+	Definitions map[string]openAPISchema `json:"definitions"`
+	Components  struct {
+		// Schemas are the schemas of an OpenAPI 3 document
+		Schemas map[string]openAPISchema `json:"schemas"`
+	} `json:"components"`
+}
+
+type openAPISchema struct {
+	Description string                      `json:"description"`
+	Deprecated  bool                        `json:"deprecated"`
+	GVKs        []schema.GroupVersionKind   `json:"x-kubernetes-group-version-kind"`
+	Properties  map[string]openAPIReference `json:"properties"`
+}
+
+type openAPIReference struct {
+	Ref   string             `json:"$ref"`
+	AllOf []openAPIReference `json:"allOf"`
+}
+
+// refersTo checks if the reference or any of its allOf references ends with the name.
+func (r openAPIReference) refersTo(name string) bool {
+	if strings.HasSuffix(r.Ref, name) {
+		return true
+	}
+	for _, ref := range r.AllOf {
+		if ref.refersTo(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// openAPIOperation is an operation with the x-kubernetes-group-version-kind extension of its kind.
+type openAPIOperation struct {
+	GVK *schema.GroupVersionKind `json:"x-kubernetes-group-version-kind"`
+}
+
+// operationMethods are the path item fields holding an operation
+var operationMethods = []string{"get", "put", "post", "delete", "options", "head", "patch"}
+
+// ParseOpenAPI parses a swagger 2.0 or OpenAPI 3 document of the Kubernetes API. The served kinds are read
+// from the operations when the document has paths, from the schemas with object metadata otherwise.
+// The release is read from info.version, it's nil when it's not a version such as in the OpenAPI 3 documents.
+func ParseOpenAPI(content []byte) (*Spec, error) {
+	var doc openAPIDocument
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	spec := &Spec{Kinds: make(map[schema.GroupVersionKind]*SpecKind)}
+	if v, err := semver.NewVersion(doc.Info.Version); err == nil {
+		spec.Release = v
+	}
+
+	schemas := doc.Definitions
+	if len(schemas) == 0 {
+		schemas = doc.Components.Schemas
+	}
+	for _, item := range doc.Paths {
+		for _, method := range operationMethods {
+			raw, ok := item[method]
+			if !ok {
+				continue
+			}
+			var op openAPIOperation
+			if err := json.Unmarshal(raw, &op); err != nil {
+				return nil, err
+			}
+			if op.GVK != nil && op.GVK.Kind != "" {
+				spec.Kinds[*op.GVK] = &SpecKind{}
+			}
+		}
+	}
+	if len(spec.Kinds) == 0 {
+		for _, s := range schemas {
+			if len(s.GVKs) == 1 && s.Properties["metadata"].refersTo("ObjectMeta") {
+				spec.Kinds[s.GVKs[0]] = &SpecKind{}
+			}
+		}
+	}
+
+	for _, s := range schemas {
+		deprecated := s.Deprecated || strings.HasPrefix(strings.ToUpper(s.Description), "DEPRECATED")
+		if !deprecated || len(s.GVKs) != 1 {
+			continue
+		}
+		kind, ok := spec.Kinds[s.GVKs[0]]
+		if !ok {
+			continue
+		}
+		kind.Deprecated = true
+		if m := deprecatedByPattern.FindStringSubmatch(s.Description); m != nil {
+			kind.Replacement = m[1]
+		}
+	}
+	return spec, nil
+}
+
+// Merge adds the kinds of another document of the same release, such as the OpenAPI 3 document of another group.
+func (s *Spec) Merge(other *Spec) {
+	for gvk, kind := range other.Kinds {
+		if existing, ok := s.Kinds[gvk]; ok && !kind.Deprecated {
+			kind = existing
+		}
+		s.Kinds[gvk] = kind
+	}
+}
+
+// FromOpenAPISpecs compares the specs of consecutive Kubernetes releases and returns a candidate deprecated
+// API version for every kind which is deprecated or removed in a release:
+//
+//   - the kind is introduced in the first release which serves it, unknown for the kinds of the oldest release
+//   - the kind is deprecated in the first release whose schema is deprecated, the patch of the releases is ignored
+//   - the kind is removed in the first release which doesn't serve it anymore
+//   - the replacement is the one mentioned by the deprecated schema, otherwise the API version serving
+//     the same kind in the release which removes it, the same group first
+//
+// The specs must have a release. The API versions are sorted by group, version and kind.
+func FromOpenAPISpecs(specs ...*Spec) (*deprecation.Versions, error) {
+	releases := make([]*Spec, 0, len(specs))
+	for _, s := range specs {
+		if s.Release == nil {
+			return nil, fmt.Errorf("the Kubernetes release of a spec is unknown")
+		}
+		releases = append(releases, s)
+	}
+	sort.SliceStable(releases, func(i, j int) bool {
+		return releases[i].Release.LessThan(releases[j].Release)
+	})
+
+	candidates := make(map[schema.GroupVersionKind]*deprecation.Version)
+	for i, release := range releases {
+		for gvk, kind := range release.Kinds {
+			c, ok := candidates[gvk]
+			if !ok {
+				c = &deprecation.Version{APIVersion: gvk.GroupVersion().String(), Kind: gvk.Kind}
+				// the kinds of the oldest release may be served by older releases
+				if i > 0 {
+					c.IntroducedInVersion = formatVersion(addReleases(release.Release, 0))
+				}
+				candidates[gvk] = c
+			}
+			if kind.Deprecated && c.DeprecatedInVersion == "" {
+				c.DeprecatedInVersion = formatVersion(addReleases(release.Release, 0))
+				c.ReplacementAPI = kind.Replacement
+			}
+		}
+		if i == 0 {
+			continue
+		}
+		for gvk := range releases[i-1].Kinds {
+			if _, ok := release.Kinds[gvk]; ok || candidates[gvk].RemovedInVersion != "" {
+				continue
+			}
+			c := candidates[gvk]
+			c.RemovedInVersion = formatVersion(addReleases(release.Release, 0))
+			if c.ReplacementAPI == "" {
+				c.ReplacementAPI = replacementIn(release, gvk)
+			}
+		}
+	}
+
+	versions := new(deprecation.Versions)
+	for _, c := range candidates {
+		if c.DeprecatedInVersion != "" || c.RemovedInVersion != "" {
+			versions.DeprecatedVersions = append(versions.DeprecatedVersions, c)
+		}
+	}
+	sortVersions(versions)
+	return versions, nil
+}
+
+// Served checks if any of the specs serves the kind.
+func Served(gvk schema.GroupVersionKind, specs ...*Spec) bool {
+	for _, s := range specs {
+		if _, ok := s.Kinds[gvk]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// replacementIn returns the API version serving the same kind in the release, the same group first
+// and the most stable version first, empty when the release doesn't serve the kind.
+func replacementIn(release *Spec, removed schema.GroupVersionKind) string {
+	var candidates []schema.GroupVersionKind
+	for gvk, kind := range release.Kinds {
+		if gvk.Kind == removed.Kind && !kind.Deprecated {
+			candidates = append(candidates, gvk)
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if (a.Group == removed.Group) != (b.Group == removed.Group) {
+			return a.Group == removed.Group
+		}
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		return compareAPIVersions(a.Version, b.Version) > 0
+	})
+	return candidates[0].GroupVersion().String()
+}
+
+// apiVersionPattern matches a Kubernetes API version such as v1, v2beta1 or v1alpha1
+var apiVersionPattern = regexp.MustCompile(`^v(\d+)(?:(alpha|beta)(\d+))?$`)
+
+// compareAPIVersions compares two Kubernetes API versions by stability, GA versions first,
+// then beta and alpha versions, the higher version first.
+func compareAPIVersions(a, b string) int {
+	rank := func(v string) [3]int {
+		m := apiVersionPattern.FindStringSubmatch(v)
+		if m == nil {
+			return [3]int{-1, 0, 0}
+		}
+		var major, stability, minor int
+		fmt.Sscan(m[1], &major)
+		switch m[2] {
+		case "":
+			stability = 2
+		case "beta":
+			stability = 1
+		}
+		if m[3] != "" {
+			fmt.Sscan(m[3], &minor)
+		}
+		return [3]int{stability, major, minor}
+	}
+	ra, rb := rank(a), rank(b)
+	for i := range ra {
+		if ra[i] != rb[i] {
+			if ra[i] > rb[i] {
+				return 1
+			}
+			return -1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,89 @@
+package generator
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	semver "github.com/hashicorp/go-version"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/wayfair-incubator/k8s-used-api-versions/pkg/deprecation"
+)
+
+func readSpec(t *testing.T, file string) *Spec {
+	content, err := ioutil.ReadFile("testdata/openapi/" + file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec, err := ParseOpenAPI(content)
+	if err != nil {
+		t.Fatalf("Unexpected error parsing %s: %v", file, err)
+	}
+	return spec
+}
+
+func TestParseOpenAPI(t *testing.T) {
+	spec := readSpec(t, "swagger-v1.15.json")
+	if spec.Release.String() != "1.15.3" || len(spec.Kinds) != 5 {
+		t.Fatalf("Unexpected spec, release %s with %d kinds", spec.Release, len(spec.Kinds))
+	}
+	deployment := spec.Kinds[schema.GroupVersionKind{Group: "apps", Version: "v1beta1", Kind: "Deployment"}]
+	if deployment == nil || !deployment.Deprecated || deployment.Replacement != "apps/v1" {
+		t.Fatalf("Expected apps/v1beta1 Deployment to be deprecated by apps/v1, got: %+v", deployment)
+	}
+
+	spec = readSpec(t, "openapi-v3-networking.k8s.io-v1beta1.json")
+	ingress := schema.GroupVersionKind{Group: "networking.k8s.io", Version: "v1beta1", Kind: "Ingress"}
+	if spec.Release != nil || len(spec.Kinds) != 1 || !spec.Kinds[ingress].Deprecated {
+		t.Fatalf("Expected only a deprecated Ingress without a release, got: %+v", spec)
+	}
+}
+
+func TestFromOpenAPISpecs(t *testing.T) {
+	v122 := readSpec(t, "swagger-v1.22.json")
+	v122.Merge(readSpec(t, "openapi-v3-networking.k8s.io-v1beta1.json"))
+	got, err := FromOpenAPISpecs(readSpec(t, "swagger-v1.16.json"), v122, readSpec(t, "swagger-v1.15.json"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []*deprecation.Version{
+		{APIVersion: "apps/v1beta1", Kind: "Deployment", DeprecatedInVersion: "v1.15.0", RemovedInVersion: "v1.16.0", ReplacementAPI: "apps/v1"},
+		{APIVersion: "batch/v2alpha1", Kind: "CronJob", RemovedInVersion: "v1.16.0"},
+		{APIVersion: "extensions/v1beta1", Kind: "Ingress", DeprecatedInVersion: "v1.16.0", RemovedInVersion: "v1.22.0", ReplacementAPI: "networking.k8s.io/v1beta1"},
+		{APIVersion: "networking.k8s.io/v1beta1", Kind: "Ingress", IntroducedInVersion: "v1.16.0", DeprecatedInVersion: "v1.22.0"},
+	}
+	if !reflect.DeepEqual(got.DeprecatedVersions, expected) {
+		for _, v := range got.DeprecatedVersions {
+			t.Logf("%+v", v)
+		}
+		t.Fatalf("Unexpected candidates")
+	}
+
+	if _, err := FromOpenAPISpecs(&Spec{}); err == nil {
+		t.Fatalf("Expected an error for a spec without a release")
+	}
+}
+
+func TestReplacementIn(t *testing.T) {
+	release := &Spec{Release: semver.Must(semver.NewVersion("v1.22.0")), Kinds: map[schema.GroupVersionKind]*SpecKind{
+		{Group: "networking.k8s.io", Version: "v1beta1", Kind: "Ingress"}: {},
+		{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"}:      {},
+		{Group: "extensions", Version: "v1beta2", Kind: "Ingress"}:        {Deprecated: true},
+		{Group: "batch", Version: "v1beta1", Kind: "CronJob"}:             {},
+		{Group: "batch", Version: "v2alpha1", Kind: "CronJob"}:            {},
+	}}
+	tests := []struct {
+		removed schema.GroupVersionKind
+		want    string
+	}{
+		{removed: schema.GroupVersionKind{Group: "extensions", Version: "v1beta1", Kind: "Ingress"}, want: "networking.k8s.io/v1"},
+		{removed: schema.GroupVersionKind{Group: "batch", Version: "v2alpha2", Kind: "CronJob"}, want: "batch/v1beta1"},
+		{removed: schema.GroupVersionKind{Group: "apps", Version: "v1beta1", Kind: "Deployment"}, want: ""},
+	}
+	for _, tt := range tests {
+		if got := replacementIn(release, tt.removed); got != tt.want {
+			t.Fatalf("replacementIn(%s) = %q, expected %q", tt.removed, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,4 @@
+package v1beta1
+
+// GroupName is the group name use in this package
+const GroupName = "apps"
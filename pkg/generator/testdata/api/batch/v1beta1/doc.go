@@ -0,0 +1,3 @@
+// +groupName=batch
+
+package v1beta1
@@ -0,0 +1,4 @@
+package v1
+
+// GroupName is the group name use in this package
+const GroupName = ""